@@ -0,0 +1,104 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sergelogvinov/helm-resources/pkg/metrics"
+	"github.com/sergelogvinov/helm-resources/pkg/recommend"
+
+	"sigs.k8s.io/yaml"
+)
+
+// configFileName is the name of the configuration file looked up in the current directory and its parents.
+const configFileName = ".helm-resources.yaml"
+
+// Config represents the configuration file of the plugin.
+type Config struct {
+	// Policy defines how recommendations are sized.
+	recommend.Policy
+	// Prometheus configures the authentication, TLS and headers of the Prometheus connection.
+	Prometheus metrics.PrometheusOptions `json:"prometheus,omitempty"`
+}
+
+// Validate checks the configuration for invalid values.
+func (c *Config) Validate() error {
+	if err := c.Policy.Validate(); err != nil {
+		return err
+	}
+
+	return c.Prometheus.Validate()
+}
+
+// loadConfig reads the configuration file from the given path.
+// If the path is empty, the file is looked up from the current directory up to the repository root.
+// A missing configuration file is not an error, an empty configuration is returned.
+func loadConfig(path string) (*Config, error) {
+	config := &Config{}
+
+	if path == "" {
+		path = findConfigFile()
+		if path == "" {
+			return config, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// findConfigFile looks up the configuration file from the current directory up to the repository root.
+func findConfigFile() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for {
+		path := filepath.Join(dir, configFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil || errors.Is(err, os.ErrPermission) {
+			return ""
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
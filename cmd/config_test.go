@@ -0,0 +1,154 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergelogvinov/helm-resources/cmd"
+)
+
+func TestFindConfigFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  []string
+		dir    string
+		expect string
+	}{
+		{
+			name:   "current directory",
+			files:  []string{"repo/.git/HEAD", "repo/charts/.helm-resources.yaml"},
+			dir:    "repo/charts",
+			expect: "repo/charts/.helm-resources.yaml",
+		},
+		{
+			name:   "parent directory",
+			files:  []string{"repo/.git/HEAD", "repo/.helm-resources.yaml"},
+			dir:    "repo/charts/web",
+			expect: "repo/.helm-resources.yaml",
+		},
+		{
+			name:  "stops at the repository root",
+			files: []string{".helm-resources.yaml", "repo/.git/HEAD"},
+			dir:   "repo/charts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+
+			for _, f := range tt.files {
+				assert.NoError(t, os.MkdirAll(filepath.Join(root, filepath.Dir(f)), 0o755))
+				assert.NoError(t, os.WriteFile(filepath.Join(root, f), nil, 0o600))
+			}
+
+			assert.NoError(t, os.MkdirAll(filepath.Join(root, tt.dir), 0o755))
+			t.Chdir(filepath.Join(root, tt.dir))
+
+			expect := ""
+			if tt.expect != "" {
+				expect = filepath.Join(root, tt.expect)
+			}
+
+			assert.Equal(t, expect, cmd.FindConfigFile())
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name: "valid config",
+			content: `cpu:
+  requestMultiplier: 1.2
+  maxRequest: 4
+memory:
+  step: 64Mi
+prometheus:
+  bearerToken: secret
+`,
+		},
+		{
+			name: "unknown field",
+			content: `cpu:
+  requestMultiplyer: 1.2
+`,
+			err: `failed to parse config file`,
+		},
+		{
+			name:    "invalid yaml",
+			content: "cpu: [",
+			err:     `failed to parse config file`,
+		},
+		{
+			name: "invalid multiplier",
+			content: `memory:
+  limitMultiplier: -2
+`,
+			err: "invalid config file",
+		},
+		{
+			name: "invalid step",
+			content: `overrides:
+  - match:
+      kind: StatefulSet
+    cpu:
+      step: -100m
+`,
+			err: "overrides[0].cpu: quantities must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".helm-resources.yaml")
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			config, err := cmd.LoadConfig(path)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotNil(t, config)
+		})
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	_, err := cmd.LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read config file")
+
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, ".git"), 0o755))
+	t.Chdir(dir)
+
+	config, err := cmd.LoadConfig("")
+	assert.NoError(t, err)
+	assert.Equal(t, &cmd.Config{}, config)
+}
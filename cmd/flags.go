@@ -17,9 +17,16 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
+	"maps"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
+
+	"github.com/sergelogvinov/helm-resources/pkg/metrics"
+	"github.com/sergelogvinov/helm-resources/pkg/recommend"
 )
 
 const (
@@ -28,31 +35,90 @@ const (
 	flagValues              = "values"
 	flagPrometheusURL       = "prometheus-url"
 	envPrometheusURL        = "PROMETHEUS_URL"
+	flagOpenCostURL         = "opencost-url"
+	envOpenCostURL          = "OPENCOST_URL"
+	flagMetricsSource       = "metrics-source"
+	envMetricsSource        = "METRICS_SOURCE"
+	flagMetricsFile         = "metrics-file"
+	envMetricsFile          = "METRICS_FILE"
+	flagSampleInterval      = "sample-interval"
 	flagMetricsWindow       = "metrics-window"
 	envMetricsWindow        = "METRICS_WINDOW"
 	flagAggregation         = "aggregation"
 	envAggregation          = "AGGREGATION"
+	flagPolicy              = "policy"
+	envPolicy               = "HELM_RESOURCES_POLICY"
+	flagStrategy            = "strategy"
+	flagTargetQoS           = "target-qos"
+	flagHorizontal          = "horizontal"
+	flagDownsize            = "downsize"
+	flagDownsizeThreshold   = "downsize-threshold"
 	flagShowStats           = "show-stats"
 	flagShowRecommendations = "show-recommendations"
+	flagShowOvercommit      = "show-overcommit"
 	flagNoHeaders           = "no-headers"
 )
 
+// Prometheus connection flags and environment variables.
+const (
+	flagPrometheusService            = "prometheus-service"
+	flagPrometheusDiscovery          = "prometheus-discovery"
+	envPrometheusService             = "PROMETHEUS_SERVICE"
+	envPrometheusToken               = "PROMETHEUS_TOKEN"
+	envPrometheusPassword            = "PROMETHEUS_PASSWORD"
+	envPrometheusHeaders             = "PROMETHEUS_HEADERS"
+	flagPrometheusTokenFile          = "prometheus-token-file"
+	envPrometheusTokenFile           = "PROMETHEUS_TOKEN_FILE"
+	flagPrometheusUsername           = "prometheus-username"
+	envPrometheusUsername            = "PROMETHEUS_USERNAME"
+	flagPrometheusKubeconfigAuth     = "prometheus-kubeconfig-auth"
+	flagPrometheusCAFile             = "prometheus-ca-file"
+	flagPrometheusCertFile           = "prometheus-cert-file"
+	flagPrometheusKeyFile            = "prometheus-key-file"
+	flagPrometheusInsecureSkipVerify = "prometheus-insecure-skip-verify"
+	flagPrometheusHeader             = "prometheus-header"
+	flagThanosMaxSourceResolution    = "thanos-max-source-resolution"
+	flagThanosPartialResponse        = "thanos-partial-response"
+	flagPrometheusQueryPreset        = "prometheus-query-preset"
+	flagPrometheusSigV4              = "prometheus-sigv4"
+	flagPrometheusSigV4Region        = "prometheus-sigv4-region"
+	flagPrometheusSigV4Profile       = "prometheus-sigv4-profile"
+)
+
 // Flags represents the command-line flags for the helm-resources command.
 type Flags struct {
 	Namespace           string
 	Output              string
 	Values              []string
 	PrometheusURL       string
+	PrometheusService   string
+	PrometheusDiscovery bool
+	Prometheus          metrics.PrometheusOptions
+	PrometheusHeaders   []string
+	PrometheusSigV4     bool
+	SigV4               metrics.SigV4Options
+	OpenCostURL         string
+	MetricsSource       string
+	MetricsFile         string
+	SampleInterval      time.Duration
 	MetricsWindow       string
 	Aggregation         string
+	Policy              string
+	Strategy            string
+	TargetQoS           string
+	Horizontal          bool
+	Downsize            bool
+	DownsizeThreshold   int
 	ShowStats           bool
 	ShowRecommendations bool
+	ShowOvercommit      bool
 	NoHeaders           bool
 }
 
 // DefaultFlags returns the default flags for the command.
 func DefaultFlags() *Flags {
 	return &Flags{
+		DownsizeThreshold:   recommend.DefaultDownsizeThreshold,
 		ShowStats:           true,
 		ShowRecommendations: true,
 		NoHeaders:           false,
@@ -62,20 +128,139 @@ func DefaultFlags() *Flags {
 // AddFlags adds the command-line flags to the provided FlagSet.
 func (f *Flags) AddFlags(flags *pflag.FlagSet) {
 	flags.StringVarP(&f.Namespace, flagNamespace, "n", "", "namespace of the release")
-	flags.StringVarP(&f.Output, flagOutput, "o", "table", "output format (table, json, yaml)")
+	flags.StringVarP(&f.Output, flagOutput, "o", "table", "output format (table, wide, json, yaml)")
 	flags.StringArrayVarP(&f.Values, flagValues, "f", []string{}, "Apply recommendations to values.yaml file (can be specified multiple times)")
 
 	// Prometheus and metrics aggregation flags
 	flags.StringVar(&f.PrometheusURL, flagPrometheusURL, withDefaultString(envPrometheusURL, ""), "Prometheus server URL for metrics (e.g., http://prometheus:9090)")
+	flags.StringVar(&f.PrometheusService, flagPrometheusService, withDefaultString(envPrometheusService, ""),
+		"In-cluster Prometheus service as namespace/name[:port][/path] reached through the Kubernetes API server proxy (e.g., monitoring/prometheus-k8s:9090)")
+	flags.BoolVar(&f.PrometheusDiscovery, flagPrometheusDiscovery, true,
+		"Discover Prometheus, VictoriaMetrics or Thanos Query in the cluster when no Prometheus URL or service is set")
+	flags.StringVar(&f.Prometheus.BearerTokenFile, flagPrometheusTokenFile, withDefaultString(envPrometheusTokenFile, ""),
+		"File with the Prometheus bearer token, the token can also be set with "+envPrometheusToken)
+	flags.StringVar(&f.Prometheus.Username, flagPrometheusUsername, withDefaultString(envPrometheusUsername, ""),
+		"Prometheus basic authentication username, the password is set with "+envPrometheusPassword)
+	flags.BoolVar(&f.Prometheus.KubeconfigAuth, flagPrometheusKubeconfigAuth, false,
+		"Authenticate to Prometheus with the kubeconfig credentials, including exec credential plugins")
+	flags.StringVar(&f.Prometheus.CAFile, flagPrometheusCAFile, "", "CA bundle file of the Prometheus server certificate")
+	flags.StringVar(&f.Prometheus.CertFile, flagPrometheusCertFile, "", "Client certificate file for Prometheus mTLS")
+	flags.StringVar(&f.Prometheus.KeyFile, flagPrometheusKeyFile, "", "Client key file for Prometheus mTLS")
+	flags.BoolVar(&f.Prometheus.InsecureSkipVerify, flagPrometheusInsecureSkipVerify, false, "Skip the verification of the Prometheus server certificate")
+	flags.StringArrayVar(&f.PrometheusHeaders, flagPrometheusHeader, nil,
+		"Header added to Prometheus requests as Name=Value, e.g. X-Scope-OrgID=tenant (can be specified multiple times)")
+	flags.StringVar(&f.Prometheus.MaxSourceResolution, flagThanosMaxSourceResolution, "",
+		"Thanos max_source_resolution of queries over long windows (raw, 5m, 1h, auto)")
+	flags.Bool(flagThanosPartialResponse, false, "Thanos partial_response of queries")
+	flags.StringVar(&f.Prometheus.Queries.Preset, flagPrometheusQueryPreset, "",
+		"Metric and label naming conventions of the Prometheus queries ("+strings.Join(metrics.PresetNames(), ", ")+"), default cadvisor")
+	flags.BoolVar(&f.PrometheusSigV4, flagPrometheusSigV4, false,
+		"Sign Prometheus requests with AWS SigV4 for Amazon Managed Service for Prometheus, with the credentials of the AWS SDK default chain")
+	flags.StringVar(&f.SigV4.Region, flagPrometheusSigV4Region, "", "AWS region of the SigV4 signature (default AWS_REGION), enables SigV4 signing")
+	flags.StringVar(&f.SigV4.Profile, flagPrometheusSigV4Profile, "", "AWS profile of the SigV4 credentials (default AWS_PROFILE), enables SigV4 signing")
+	flags.StringVar(&f.OpenCostURL, flagOpenCostURL, withDefaultString(envOpenCostURL, ""), "OpenCost API URL for usage and cost (e.g., http://opencost:9003)")
+	flags.StringVar(&f.MetricsSource, flagMetricsSource, withDefaultString(envMetricsSource, metrics.SourceAuto),
+		"Metrics source ("+strings.Join(metrics.SourceNames(), ", ")+"), or a comma-separated list in fallback order")
+	flags.StringVar(&f.MetricsFile, flagMetricsFile, withDefaultString(envMetricsFile, ""),
+		"CSV or JSON file with the usage of the containers, exported from another monitoring system")
+	flags.DurationVar(&f.SampleInterval, flagSampleInterval, f.SampleInterval,
+		"Sample metrics-server usage at this interval over the metrics window instead of a single snapshot (e.g., 30s)")
 	flags.StringVar(&f.MetricsWindow, flagMetricsWindow, withDefaultString(envMetricsWindow, "1h"), "Time window for metrics queries (e.g., 5m, 1h, 24h)")
 	flags.StringVar(&f.Aggregation, flagAggregation, withDefaultString(envAggregation, "avg"), "Aggregation function for metrics (avg, max)")
 
+	// Recommendation flags
+	flags.StringVar(&f.Policy, flagPolicy, withDefaultString(envPolicy, ""), "Sizing policy file (default: "+configFileName+" in the current directory or its parents)")
+	flags.StringVar(&f.Strategy, flagStrategy, recommend.StrategyDefault,
+		"Sizing strategy ("+strings.Join((*recommend.Policy)(nil).StrategyNames(), ", ")+", or a strategy from the policy file)")
+	flags.StringVar(&f.TargetQoS, flagTargetQoS, "", "Target QoS class ("+strings.Join(recommend.TargetQoSNames(), ", ")+")")
+	flags.BoolVar(&f.Horizontal, flagHorizontal, f.Horizontal, "Advise scaling stateless workloads out to more replicas instead of raising CPU requests above one core")
+	flags.BoolVar(&f.Downsize, flagDownsize, f.Downsize, "Recommend lower requests and limits for over-provisioned containers")
+	flags.IntVar(&f.DownsizeThreshold, flagDownsizeThreshold, f.DownsizeThreshold, "Usage to request ratio (%) below which a container is over-provisioned")
+
 	// Output formatting flags
 	flags.BoolVar(&f.ShowStats, flagShowStats, f.ShowStats, "Show resource statistics")
 	flags.BoolVar(&f.ShowRecommendations, flagShowRecommendations, f.ShowRecommendations, "Show resource recommendations")
+	flags.BoolVar(&f.ShowOvercommit, flagShowOvercommit, f.ShowOvercommit, "Show requests and limits overcommit of the nodes hosting release pods")
 	flags.BoolVar(&f.NoHeaders, flagNoHeaders, f.NoHeaders, "Do not print table headers")
 }
 
+// prometheusOptions returns the Prometheus connection options of the configuration file overridden by
+// the flags and environment variables which are set.
+func (f *Flags) prometheusOptions(flags *pflag.FlagSet, config metrics.PrometheusOptions) (metrics.PrometheusOptions, error) {
+	opts := config
+
+	for _, o := range []struct {
+		value string
+		dst   *string
+	}{
+		{os.Getenv(envPrometheusToken), &opts.BearerToken},
+		{f.Prometheus.BearerTokenFile, &opts.BearerTokenFile},
+		{f.Prometheus.Username, &opts.Username},
+		{os.Getenv(envPrometheusPassword), &opts.Password},
+		{f.Prometheus.CAFile, &opts.CAFile},
+		{f.Prometheus.CertFile, &opts.CertFile},
+		{f.Prometheus.KeyFile, &opts.KeyFile},
+		{f.Prometheus.MaxSourceResolution, &opts.MaxSourceResolution},
+		{f.Prometheus.Queries.Preset, &opts.Queries.Preset},
+	} {
+		if o.value != "" {
+			*o.dst = o.value
+		}
+	}
+
+	opts.KubeconfigAuth = opts.KubeconfigAuth || f.Prometheus.KubeconfigAuth
+	opts.InsecureSkipVerify = opts.InsecureSkipVerify || f.Prometheus.InsecureSkipVerify
+
+	if f.PrometheusSigV4 || f.SigV4.Region != "" || f.SigV4.Profile != "" {
+		sigV4 := metrics.SigV4Options{}
+		if opts.SigV4 != nil {
+			sigV4 = *opts.SigV4
+		}
+
+		if f.SigV4.Region != "" {
+			sigV4.Region = f.SigV4.Region
+		}
+
+		if f.SigV4.Profile != "" {
+			sigV4.Profile = f.SigV4.Profile
+		}
+
+		opts.SigV4 = &sigV4
+	}
+
+	if flags.Changed(flagThanosPartialResponse) {
+		partialResponse, err := flags.GetBool(flagThanosPartialResponse)
+		if err != nil {
+			return opts, err
+		}
+
+		opts.PartialResponse = &partialResponse
+	}
+
+	headers := f.PrometheusHeaders
+	if env := os.Getenv(envPrometheusHeaders); env != "" && len(headers) == 0 {
+		headers = strings.Split(env, ",")
+	}
+
+	if len(headers) > 0 {
+		opts.Headers = maps.Clone(opts.Headers)
+		if opts.Headers == nil {
+			opts.Headers = map[string]string{}
+		}
+	}
+
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return opts, fmt.Errorf("invalid Prometheus header %q, must be Name=Value", header)
+		}
+
+		opts.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return opts, opts.Validate()
+}
+
 func withDefaultString(key string, def string) string {
 	val, ok := os.LookupEnv(key)
 	if !ok {
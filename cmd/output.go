@@ -17,27 +17,35 @@ limitations under the License.
 package cmd
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"text/tabwriter"
 
+	"github.com/sergelogvinov/helm-resources/pkg/recommend"
 	"github.com/sergelogvinov/helm-resources/pkg/resources"
 
 	"sigs.k8s.io/yaml"
 )
 
-const none = "-"
+const (
+	none = "-"
 
-func outputJSON(resources []resources.ResourceInfo) error {
+	outputWide = "wide"
+)
+
+func outputJSON(infos []resources.ResourceInfo) error {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
 
-	return encoder.Encode(resources)
+	return encoder.Encode(sortByRisk(infos))
 }
 
-func outputYAML(resources []resources.ResourceInfo) error {
-	yamlData, err := yaml.Marshal(resources)
+func outputYAML(infos []resources.ResourceInfo) error {
+	yamlData, err := yaml.Marshal(sortByRisk(infos))
 	if err != nil {
 		return err
 	}
@@ -47,26 +55,78 @@ func outputYAML(resources []resources.ResourceInfo) error {
 	return nil
 }
 
-func outputTable(f *Flags, resources []resources.ResourceInfo) error {
+// sortByRisk returns a copy of the containers sorted by risk score, the most urgent first.
+func sortByRisk(infos []resources.ResourceInfo) []resources.ResourceInfo {
+	sorted := slices.Clone(infos)
+
+	slices.SortStableFunc(sorted, func(a, b resources.ResourceInfo) int {
+		return cmp.Compare(b.RiskScore, a.RiskScore)
+	})
+
+	return sorted
+}
+
+func outputTable(f *Flags, infos []resources.ResourceInfo) error {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 
+	wide := f.Output == outputWide
+
 	if !f.NoHeaders {
-		fmt.Fprintf(w, "KIND\tNAME\tREPLICAS\tCONTAINER\tREQUESTS (CPU/MEM)\tLIMITS (CPU/MEM)\tUSAGE (CPU/MEM)\n")
+		fmt.Fprintf(w, "KIND\tNAME\tREPLICAS\tQOS\tCONTAINER\tREQUESTS (CPU/MEM)\tLIMITS (CPU/MEM)\tUSAGE (CPU/MEM)")
+
+		if wide {
+			fmt.Fprintf(w, "\tCPU (MIN/MEDIAN/MAX)\tMEM (MIN/MEDIAN/MAX)\tOUTLIERS\tVPA TARGET (CPU/MEM)\tCOST\tSOURCE")
+		}
+
+		fmt.Fprintln(w)
 	}
 
-	for _, res := range resources {
+	for _, res := range infos {
 		requestsInfo := formatResourceValues(res.CPURequest, res.MemRequest)
 		limitsInfo := formatResourceValues(res.CPULimit, res.MemLimit)
 		usageInfo := formatResourceValues(res.CPUUsage, res.MemUsage)
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
 			res.Kind,
 			res.Name,
-			res.Replicas,
+			formatReplicas(res),
+			res.QoSClass,
 			res.Container,
 			requestsInfo,
 			limitsInfo,
 			usageInfo)
+
+		if wide {
+			outliers := none
+			if names := resources.Outliers(res.Pods); len(names) > 0 {
+				outliers = strings.Join(names, ", ")
+			}
+
+			vpaTarget := none
+			if res.VPA != nil {
+				vpaTarget = formatResourceValues(res.VPA.Target.CPU, res.VPA.Target.Mem)
+			}
+
+			cost := none
+			if res.Cost != nil {
+				cost = fmt.Sprintf("%.2f/%s", res.Cost.Total, res.Cost.Window)
+			}
+
+			source := none
+			if res.MetricsSource != "" {
+				source = res.MetricsSource
+			}
+
+			fmt.Fprintf(w, "\t%s\t%s\t%s\t%s\t%s\t%s",
+				formatDistribution(resources.CPUDistribution(res.Pods), formatCPU),
+				formatDistribution(resources.MemDistribution(res.Pods), formatMemory),
+				outliers,
+				vpaTarget,
+				cost,
+				source)
+		}
+
+		fmt.Fprintln(w)
 	}
 
 	return w.Flush()
@@ -81,7 +141,7 @@ func outputTableRecommendations(f *Flags, recommendations []resources.ResourceRe
 				fmt.Printf("\nResource recommendations to adjust:\n\n")
 			}
 
-			fmt.Fprintf(w, "KIND\tNAME\tCONTAINER\tREQUESTS (CPU/MEM)\tREQUESTS DIFF (%%)\tLIMITS (CPU/MEM)\tLIMITS DIFF (%%)\tUSAGE (CPU/MEM)\n")
+			fmt.Fprintf(w, "SEVERITY\tKIND\tNAME\tCONTAINER\tREQUESTS (CPU/MEM)\tREQUESTS DIFF (%%)\tLIMITS (CPU/MEM)\tLIMITS DIFF (%%)\tUSAGE (CPU/MEM)\tNOTES\n")
 		}
 
 		for _, rec := range recommendations {
@@ -89,9 +149,19 @@ func outputTableRecommendations(f *Flags, recommendations []resources.ResourceRe
 			requestsDiff := formatPercentageDiff(rec.CurrentCPURequest, rec.RecommendedCPURequest, rec.CurrentMemRequest, rec.RecommendedMemRequest)
 			limitsInfo := formatResourceValues(rec.RecommendedCPULimit, rec.RecommendedMemLimit)
 			limitsDiff := formatPercentageDiff(rec.CurrentCPULimit, rec.RecommendedCPULimit, rec.CurrentMemLimit, rec.RecommendedMemLimit)
+
+			if rec.RemoveCPULimit {
+				limitsInfo = "unset/" + formatMemory(rec.RecommendedMemLimit)
+			}
 			usageInfo := formatResourceValues(rec.CPUUsage, rec.MemUsage)
 
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			notes := none
+			if len(rec.Notes) > 0 {
+				notes = strings.Join(rec.Notes, "; ")
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				rec.Severity,
 				rec.Kind,
 				rec.Name,
 				rec.Container,
@@ -100,15 +170,146 @@ func outputTableRecommendations(f *Flags, recommendations []resources.ResourceRe
 				limitsInfo,
 				limitsDiff,
 				usageInfo,
+				notes,
 			)
 		}
 
-		return w.Flush()
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		outputReclaimable(recommendations)
 	}
 
 	return nil
 }
 
+func outputReclaimable(recommendations []resources.ResourceRecommendation) {
+	type reclaimable struct {
+		cpu, mem int64
+	}
+
+	var releases []string
+
+	totals := map[string]*reclaimable{}
+
+	for _, rec := range recommendations {
+		if rec.ReclaimableCPU == 0 && rec.ReclaimableMem == 0 {
+			continue
+		}
+
+		total, ok := totals[rec.Release]
+		if !ok {
+			total = &reclaimable{}
+			totals[rec.Release] = total
+			releases = append(releases, rec.Release)
+		}
+
+		total.cpu += rec.ReclaimableCPU
+		total.mem += rec.ReclaimableMem
+	}
+
+	for _, release := range releases {
+		fmt.Printf("\nReclaimable requests of release %s (CPU/MEM): %s\n",
+			release, formatResourceValues(totals[release].cpu, totals[release].mem))
+	}
+}
+
+func outputTableQuotaHeadroom(f *Flags, headroom []recommend.QuotaHeadroom) error {
+	if len(headroom) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	if !f.NoHeaders {
+		fmt.Printf("\nResource quota headroom after recommendations:\n\n")
+		fmt.Fprintf(w, "QUOTA\tRESOURCE\tHARD\tUSED\tCHANGE\tHEADROOM\n")
+	}
+
+	for _, h := range headroom {
+		change := formatQuotaValue(h.Resource, h.Change)
+		if h.Change > 0 {
+			change = "+" + change
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			h.Quota,
+			h.Resource,
+			formatQuotaValue(h.Resource, h.Hard),
+			formatQuotaValue(h.Resource, h.Used),
+			change,
+			formatQuotaValue(h.Resource, h.Headroom()),
+		)
+	}
+
+	return w.Flush()
+}
+
+func outputTableOvercommit(f *Flags, overcommit []recommend.NodeOvercommit) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	if !f.NoHeaders {
+		fmt.Printf("\nOvercommit of the nodes hosting release pods:\n\n")
+		fmt.Fprintf(w, "NODE\tALLOCATABLE (CPU/MEM)\tREQUESTS (CPU/MEM)\tLIMITS (CPU/MEM)\tOVERCOMMIT (CPU/MEM)\tTOP CONTRIBUTORS (LIMITS OVER REQUESTS)\n")
+	}
+
+	for _, n := range overcommit {
+		contributors := make([]string, 0, len(n.Contributors))
+		for _, c := range n.Contributors {
+			contributors = append(contributors, fmt.Sprintf("%s/%s x%d %s", c.Name, c.Container, c.Pods, formatResourceValues(c.CPU, c.Mem)))
+		}
+
+		top := none
+		if len(contributors) > 0 {
+			top = strings.Join(contributors, ", ")
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.0f%%/%.0f%%\t%s\n",
+			n.Node,
+			formatResourceValues(n.AllocatableCPU, n.AllocatableMem),
+			formatResourceValues(n.RequestedCPU, n.RequestedMem),
+			formatResourceValues(n.LimitedCPU, n.LimitedMem),
+			n.CPURatio()*100,
+			n.MemRatio()*100,
+			top,
+		)
+	}
+
+	return w.Flush()
+}
+
+func formatQuotaValue(name string, value int64) string {
+	format := formatMemory
+	if strings.HasSuffix(name, ".cpu") {
+		format = formatCPU
+	}
+
+	if value < 0 {
+		return "-" + format(-value)
+	}
+
+	return format(value)
+}
+
+// formatReplicas returns the replicas of the workload, or the min-max (current) replicas of its autoscaler.
+func formatReplicas(res resources.ResourceInfo) string {
+	if a := res.Autoscaler; a != nil {
+		return fmt.Sprintf("%d-%d (%d)", a.MinReplicas, a.MaxReplicas, a.CurrentReplicas)
+	}
+
+	return res.Replicas
+}
+
+// formatDistribution returns the min/median/max usage across pods.
+func formatDistribution(d resources.Distribution, format func(int64) string) string {
+	if d.Max == 0 {
+		return none
+	}
+
+	return fmt.Sprintf("%s/%s/%s", format(d.Min), format(d.Median), format(d.Max))
+}
+
 func formatResourceValues(cpu, memory int64) string {
 	cpuStr := formatCPU(cpu)
 	memStr := formatMemory(memory)
@@ -137,16 +338,7 @@ func formatMemory(bytes int64) string {
 		return none
 	}
 
-	switch {
-	case bytes >= 1024*1024*1024:
-		return fmt.Sprintf("%.1fGi", float64(bytes)/(1024*1024*1024))
-	case bytes >= 1024*1024:
-		return fmt.Sprintf("%.0fMi", float64(bytes)/(1024*1024))
-	case bytes >= 1024:
-		return fmt.Sprintf("%.0fKi", float64(bytes)/1024)
-	}
-
-	return fmt.Sprintf("%d", bytes)
+	return resources.FormatMemory(bytes)
 }
 
 func formatPercentageDiff(currentCPU, recommendedCPU, currentMem, recommendedMem int64) string {
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -27,6 +28,7 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli"
 
+	"github.com/sergelogvinov/helm-resources/pkg/cluster"
 	"github.com/sergelogvinov/helm-resources/pkg/metrics"
 	"github.com/sergelogvinov/helm-resources/pkg/patch"
 	"github.com/sergelogvinov/helm-resources/pkg/recommend"
@@ -62,6 +64,18 @@ func newResourcesCommand() *cobra.Command {
 			"  helm resources my-release --namespace production",
 			"  helm resources my-release --output json",
 			"  helm resources my-release --values values.yaml",
+			"  helm resources my-release --downsize --downsize-threshold 30",
+			"  helm resources my-release --strategy cost-optimized",
+			"  helm resources my-release --target-qos guaranteed --values values.yaml",
+			"  helm resources my-release --show-overcommit",
+			"  helm resources my-release --horizontal --values values.yaml",
+			"  helm resources my-release --metrics-source prometheus,metrics-server",
+			"  helm resources my-release --metrics-source metrics-server --metrics-window 10m --sample-interval 30s",
+			"  helm resources my-release --metrics-source file --metrics-file usage.csv --values values.yaml",
+			"  helm resources my-release --opencost-url http://opencost.opencost.svc:9003 -o wide",
+			"  helm resources my-release --prometheus-service monitoring/prometheus-k8s:9090",
+			"  helm resources my-release --prometheus-url https://mimir.example.com/prometheus --prometheus-header X-Scope-OrgID=team-a",
+			"  helm resources my-release --prometheus-url https://aps-workspaces.us-west-2.amazonaws.com/workspaces/ws-1234 --prometheus-sigv4",
 		}, "\n"),
 		Args: cobra.ExactArgs(1),
 		RunE: opts.RunResources,
@@ -76,6 +90,30 @@ func newResourcesCommand() *cobra.Command {
 func (o *CommandOptions) RunResources(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
+	config, err := loadConfig(o.Flags.Policy)
+	if err != nil {
+		return err
+	}
+
+	strategy, err := config.Strategy(o.Flags.Strategy)
+	if err != nil {
+		return err
+	}
+
+	if o.Flags.TargetQoS != "" && !slices.Contains(recommend.TargetQoSNames(), o.Flags.TargetQoS) {
+		return fmt.Errorf("unknown target QoS %q, available targets: %s", o.Flags.TargetQoS, strings.Join(recommend.TargetQoSNames(), ", "))
+	}
+
+	sources, err := metrics.ParseSources(o.Flags.MetricsSource)
+	if err != nil {
+		return err
+	}
+
+	prometheusOptions, err := o.Flags.prometheusOptions(cmd.Flags(), config.Prometheus)
+	if err != nil {
+		return err
+	}
+
 	settings := cli.New()
 	if o.Flags.Namespace != "" {
 		settings.SetNamespace(o.Flags.Namespace)
@@ -93,29 +131,101 @@ func (o *CommandOptions) RunResources(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	config, err := settings.RESTClientGetter().ToRESTConfig()
+	restConfig, err := settings.RESTClientGetter().ToRESTConfig()
 	if err != nil {
 		return fmt.Errorf("failed to get kubernetes config: %w", err)
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
+	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
 
-	metricsClient, err := metrics.New(o.Flags.PrometheusURL, o.Flags.MetricsWindow, o.Flags.Aggregation, config)
+	statistics := strategy.Statistics()
+
+	// Discovered services are reached through the API server proxy with the kubeconfig credentials,
+	// authentication and TLS options require a Prometheus URL.
+	prometheusService := o.Flags.PrometheusService
+	if o.Flags.PrometheusURL == "" && prometheusService == "" && o.Flags.PrometheusDiscovery &&
+		!prometheusOptions.HasAuthOrTLS() && slices.Contains(sources, metrics.SourcePrometheus) {
+		endpoint, err := metrics.DiscoverPrometheus(ctx, restConfig)
+
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Warning: failed to discover Prometheus: %v\n", err)
+		case endpoint != nil:
+			prometheusService = endpoint.Service
+			fmt.Fprintf(os.Stderr, "Using %s %s discovered in the cluster\n", endpoint.Kind, endpoint.Service)
+		default:
+			fmt.Fprintf(os.Stderr, "No Prometheus found in the cluster, set --%s or --%s for the usage over the metrics window\n",
+				flagPrometheusURL, flagPrometheusService)
+		}
+	}
+
+	metricsClient, err := metrics.New(metrics.Options{
+		PrometheusURL:     o.Flags.PrometheusURL,
+		PrometheusService: prometheusService,
+		Prometheus:        prometheusOptions,
+		OpenCostURL:       o.Flags.OpenCostURL,
+		MetricsFile:       o.Flags.MetricsFile,
+		Sources:           sources,
+		MetricsWindow:     o.Flags.MetricsWindow,
+		SampleInterval:    o.Flags.SampleInterval,
+		Aggregation:       o.Flags.Aggregation,
+		Statistics:        statistics,
+		Warnings:          os.Stderr,
+	}, restConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create metrics client: %w", err)
 	}
 
+	if o.Flags.SampleInterval > 0 {
+		if err := metricsClient.Sample(ctx, release.Namespace, os.Stderr); err != nil {
+			return fmt.Errorf("failed to sample metrics: %w", err)
+		}
+	}
+
 	resInfos, err := apps.ExtractResourcesFromHelmRelease(ctx, clientset, metricsClient, release)
 	if err != nil {
 		return fmt.Errorf("failed to extract resources: %w", err)
 	}
 
+	constraints, err := cluster.GetConstraints(ctx, clientset, release.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get namespace constraints: %w", err)
+	}
+
 	var errs error
 
-	recommendations := recommend.AnalyzeRecommendations(resInfos)
+	recommend.ScoreRisk(resInfos)
+
+	recommendations := recommend.AnalyzeRecommendations(resInfos, recommend.Options{
+		Policy:            &config.Policy,
+		Strategy:          strategy,
+		TargetQoS:         o.Flags.TargetQoS,
+		Constraints:       constraints,
+		Horizontal:        o.Flags.Horizontal,
+		Downsize:          o.Flags.Downsize,
+		DownsizeThreshold: o.Flags.DownsizeThreshold,
+	})
+
+	headroom := recommend.CheckQuotas(resInfos, recommendations, constraints.Quotas)
+
+	var overcommit []recommend.NodeOvercommit
+
+	if o.Flags.ShowOvercommit || slices.ContainsFunc(recommendations, resources.ResourceRecommendation.RaisesRequests) {
+		nodes, err := cluster.GetNodes(ctx, clientset)
+		if err != nil {
+			return fmt.Errorf("failed to get nodes: %w", err)
+		}
+
+		recommend.CheckNodeFit(release.Namespace, resInfos, recommendations, nodes)
+
+		if o.Flags.ShowOvercommit {
+			overcommit = recommend.Overcommit(release.Namespace, resInfos, nodes)
+		}
+	}
+
 	if len(o.Flags.Values) > 0 && len(recommendations) > 0 {
 		if err := applyRecommendationsToValuesFiles(recommendations, o.Flags.Values); err != nil {
 			errs = multierr.Append(errs, err)
@@ -142,6 +252,16 @@ func (o *CommandOptions) RunResources(cmd *cobra.Command, args []string) error {
 			if err = outputTableRecommendations(o.Flags, recommendations); err != nil {
 				errs = multierr.Append(errs, err)
 			}
+
+			if err = outputTableQuotaHeadroom(o.Flags, headroom); err != nil {
+				errs = multierr.Append(errs, err)
+			}
+		}
+
+		if o.Flags.ShowOvercommit && len(overcommit) > 0 {
+			if err = outputTableOvercommit(o.Flags, overcommit); err != nil {
+				errs = multierr.Append(errs, err)
+			}
 		}
 	}
 
@@ -172,6 +292,10 @@ func applyRecommendationsToValuesFiles(recommendations []resources.ResourceRecom
 		retryRecommendations = []resources.ResourceRecommendation{}
 
 		for _, r := range recommendations {
+			if !r.HasChanges() {
+				continue
+			}
+
 			newText, err := patch.ApplyPatchesToYaml(updatedText, r)
 			if err != nil {
 				if !errors.Is(err, patch.ErrNotFound) {
@@ -0,0 +1,179 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cluster reads the cluster constraints recommendations have to fit in.
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// QuotaRequestsCPU is the quota of CPU requests, in milli-cores.
+	QuotaRequestsCPU = "requests.cpu"
+	// QuotaRequestsMemory is the quota of memory requests, in bytes.
+	QuotaRequestsMemory = "requests.memory"
+	// QuotaLimitsCPU is the quota of CPU limits, in milli-cores.
+	QuotaLimitsCPU = "limits.cpu"
+	// QuotaLimitsMemory is the quota of memory limits, in bytes.
+	QuotaLimitsMemory = "limits.memory"
+)
+
+// Constraints are the LimitRange and ResourceQuota constraints of a namespace.
+type Constraints struct {
+	// Limits are the container limits of all LimitRanges combined, the most restrictive value wins.
+	Limits ContainerLimits
+	Quotas []Quota
+}
+
+// ContainerLimits are the constraints of the container requests and limits.
+type ContainerLimits struct {
+	CPU    ResourceLimits // milli-cores
+	Memory ResourceLimits // bytes
+}
+
+// ResourceLimits are the LimitRange constraints of a resource, zero values are not constrained.
+type ResourceLimits struct {
+	Min                  int64
+	Max                  int64
+	MaxLimitRequestRatio float64
+}
+
+// Quota is a ResourceQuota of the compute resources, keyed by QuotaRequestsCPU, QuotaLimitsMemory, etc.
+type Quota struct {
+	Name string
+	Hard map[string]int64
+	Used map[string]int64
+}
+
+// GetConstraints returns the LimitRange and ResourceQuota constraints of the namespace.
+// Objects the user is not allowed to list are skipped.
+func GetConstraints(ctx context.Context, clientset kubernetes.Interface, namespace string) (*Constraints, error) {
+	constraints := &Constraints{}
+
+	limitRanges, err := clientset.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !apierrors.IsForbidden(err) {
+		return nil, fmt.Errorf("failed to list limit ranges: %w", err)
+	}
+
+	if limitRanges != nil {
+		for _, lr := range limitRanges.Items {
+			for _, item := range lr.Spec.Limits {
+				if item.Type == v1.LimitTypeContainer {
+					constraints.Limits.add(item)
+				}
+			}
+		}
+	}
+
+	quotas, err := clientset.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !apierrors.IsForbidden(err) {
+		return nil, fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+
+	if quotas != nil {
+		for _, q := range quotas.Items {
+			quota := Quota{
+				Name: q.Name,
+				Hard: computeResources(q.Status.Hard),
+				Used: computeResources(q.Status.Used),
+			}
+
+			if len(quota.Hard) == 0 {
+				quota.Hard = computeResources(q.Spec.Hard)
+			}
+
+			if len(quota.Hard) > 0 {
+				constraints.Quotas = append(constraints.Quotas, quota)
+			}
+		}
+	}
+
+	return constraints, nil
+}
+
+// RequiresCPULimit reports whether pods without a CPU limit would be rejected by the namespace.
+func (c *Constraints) RequiresCPULimit() bool {
+	if c == nil {
+		return false
+	}
+
+	for _, q := range c.Quotas {
+		if _, ok := q.Hard[QuotaLimitsCPU]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *ContainerLimits) add(item v1.LimitRangeItem) {
+	l.CPU.add(item, v1.ResourceCPU, milliValue)
+	l.Memory.add(item, v1.ResourceMemory, value)
+}
+
+func (l *ResourceLimits) add(item v1.LimitRangeItem, name v1.ResourceName, quantity func(resource.Quantity) int64) {
+	if q, ok := item.Min[name]; ok {
+		l.Min = max(l.Min, quantity(q))
+	}
+
+	if q, ok := item.Max[name]; ok {
+		if v := quantity(q); l.Max == 0 || v < l.Max {
+			l.Max = v
+		}
+	}
+
+	if q, ok := item.MaxLimitRequestRatio[name]; ok {
+		if v := q.AsApproximateFloat64(); l.MaxLimitRequestRatio == 0 || v < l.MaxLimitRequestRatio {
+			l.MaxLimitRequestRatio = v
+		}
+	}
+}
+
+// computeResources returns the CPU and memory quotas, "cpu" and "memory" are aliases of the requests quotas.
+func computeResources(list v1.ResourceList) map[string]int64 {
+	res := map[string]int64{}
+
+	for name, q := range list {
+		switch name {
+		case v1.ResourceCPU, v1.ResourceRequestsCPU:
+			res[QuotaRequestsCPU] = milliValue(q)
+		case v1.ResourceMemory, v1.ResourceRequestsMemory:
+			res[QuotaRequestsMemory] = value(q)
+		case v1.ResourceLimitsCPU:
+			res[QuotaLimitsCPU] = milliValue(q)
+		case v1.ResourceLimitsMemory:
+			res[QuotaLimitsMemory] = value(q)
+		}
+	}
+
+	return res
+}
+
+func milliValue(q resource.Quantity) int64 {
+	return q.MilliValue()
+}
+
+func value(q resource.Quantity) int64 {
+	return q.Value()
+}
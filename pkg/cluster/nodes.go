@@ -0,0 +1,256 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+var nodeSelectorOperators = map[v1.NodeSelectorOperator]selection.Operator{
+	v1.NodeSelectorOpIn:           selection.In,
+	v1.NodeSelectorOpNotIn:        selection.NotIn,
+	v1.NodeSelectorOpExists:       selection.Exists,
+	v1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	v1.NodeSelectorOpGt:           selection.GreaterThan,
+	v1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// Node is a schedulable node with its allocatable resources and the requests of the pods running on it.
+type Node struct {
+	Name   string
+	Labels map[string]string
+	Taints []v1.Taint
+	// Allocatable resources of the node
+	AllocatableCPU int64 // milli-cores
+	AllocatableMem int64 // bytes
+	// Requests of all pods on the node
+	RequestedCPU int64 // milli-cores
+	RequestedMem int64 // bytes
+	// Limits of all pods on the node, containers without limits are not counted
+	LimitedCPU int64 // milli-cores
+	LimitedMem int64 // bytes
+	Pods       []Pod
+}
+
+// Pod is a pod running on a node with its requests.
+type Pod struct {
+	Namespace string
+	Name      string
+	CPU       int64 // milli-cores
+	Mem       int64 // bytes
+}
+
+// podListPageSize is the page size of the cluster-wide pod list.
+const podListPageSize = 500
+
+// GetNodes returns the schedulable nodes with the requests of all pods running on them.
+// It returns no nodes when the user is not allowed to list nodes or pods of all namespaces.
+func GetNodes(ctx context.Context, clientset kubernetes.Interface) ([]Node, error) {
+	nodeList, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	nodes := make([]Node, 0, len(nodeList.Items))
+	index := make(map[string]int, len(nodeList.Items))
+
+	for _, n := range nodeList.Items {
+		if n.Spec.Unschedulable {
+			continue
+		}
+
+		index[n.Name] = len(nodes)
+		nodes = append(nodes, Node{
+			Name:           n.Name,
+			Labels:         n.Labels,
+			Taints:         n.Spec.Taints,
+			AllocatableCPU: n.Status.Allocatable.Cpu().MilliValue(),
+			AllocatableMem: n.Status.Allocatable.Memory().Value(),
+		})
+	}
+
+	opts := metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+		Limit:         podListPageSize,
+	}
+
+	for {
+		podList, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, opts)
+		if err != nil {
+			if apierrors.IsForbidden(err) {
+				return nil, nil
+			}
+
+			return nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+
+		for _, p := range podList.Items {
+			i, ok := index[p.Spec.NodeName]
+			if !ok {
+				continue
+			}
+
+			cpu, mem := PodRequests(&p.Spec)
+			cpuLimit, memLimit := PodLimits(&p.Spec)
+
+			nodes[i].RequestedCPU += cpu
+			nodes[i].RequestedMem += mem
+			nodes[i].LimitedCPU += cpuLimit
+			nodes[i].LimitedMem += memLimit
+			nodes[i].Pods = append(nodes[i].Pods, Pod{Namespace: p.Namespace, Name: p.Name, CPU: cpu, Mem: mem})
+		}
+
+		if opts.Continue = podList.Continue; opts.Continue == "" {
+			break
+		}
+	}
+
+	return nodes, nil
+}
+
+// PodRequests returns the CPU and memory requests of the pod the scheduler accounts for:
+// the sum of the containers, at least the largest init container, plus the pod overhead.
+func PodRequests(spec *v1.PodSpec) (int64, int64) {
+	var cpu, mem int64
+
+	for _, c := range spec.Containers {
+		cpu += c.Resources.Requests.Cpu().MilliValue()
+		mem += c.Resources.Requests.Memory().Value()
+	}
+
+	for _, c := range spec.InitContainers {
+		cpu = max(cpu, c.Resources.Requests.Cpu().MilliValue())
+		mem = max(mem, c.Resources.Requests.Memory().Value())
+	}
+
+	cpu += spec.Overhead.Cpu().MilliValue()
+	mem += spec.Overhead.Memory().Value()
+
+	return cpu, mem
+}
+
+// PodLimits returns the CPU and memory limits of the pod, computed the same way as the requests.
+func PodLimits(spec *v1.PodSpec) (int64, int64) {
+	var cpu, mem int64
+
+	for _, c := range spec.Containers {
+		cpu += c.Resources.Limits.Cpu().MilliValue()
+		mem += c.Resources.Limits.Memory().Value()
+	}
+
+	for _, c := range spec.InitContainers {
+		cpu = max(cpu, c.Resources.Limits.Cpu().MilliValue())
+		mem = max(mem, c.Resources.Limits.Memory().Value())
+	}
+
+	if cpu > 0 {
+		cpu += spec.Overhead.Cpu().MilliValue()
+	}
+
+	if mem > 0 {
+		mem += spec.Overhead.Memory().Value()
+	}
+
+	return cpu, mem
+}
+
+// Schedulable reports whether pods with the scheduling constraints can be placed on the node,
+// it checks the node selector, the required node affinity and the NoSchedule and NoExecute taints.
+func (n *Node) Schedulable(s *resources.SchedulingConstraints) bool {
+	var tolerations []v1.Toleration
+
+	if s != nil {
+		if !labels.SelectorFromSet(s.NodeSelector).Matches(labels.Set(n.Labels)) {
+			return false
+		}
+
+		if s.RequiredNodeAffinity != nil && !n.matchesNodeSelector(s.RequiredNodeAffinity) {
+			return false
+		}
+
+		tolerations = s.Tolerations
+	}
+
+	for _, taint := range n.Taints {
+		if taint.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		if !tolerates(tolerations, &taint) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesNodeSelector reports whether the node matches any of the node selector terms.
+func (n *Node) matchesNodeSelector(ns *v1.NodeSelector) bool {
+	for _, term := range ns.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+
+		if matchesRequirements(term.MatchExpressions, labels.Set(n.Labels)) &&
+			matchesRequirements(term.MatchFields, labels.Set{"metadata.name": n.Name}) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesRequirements(reqs []v1.NodeSelectorRequirement, set labels.Set) bool {
+	for _, req := range reqs {
+		op, ok := nodeSelectorOperators[req.Operator]
+		if !ok {
+			return false
+		}
+
+		r, err := labels.NewRequirement(req.Key, op, req.Values)
+		if err != nil || !r.Matches(set) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func tolerates(tolerations []v1.Toleration, taint *v1.Taint) bool {
+	for _, t := range tolerations {
+		if t.ToleratesTaint(klog.Background(), taint, false) {
+			return true
+		}
+	}
+
+	return false
+}
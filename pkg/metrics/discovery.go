@@ -0,0 +1,214 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// prometheusOperatorService is the governing service the Prometheus Operator creates for Prometheus instances.
+	prometheusOperatorService = "prometheus-operated"
+	// discoveryTimeout is the overall timeout of the discovery, the candidates are probed in parallel.
+	discoveryTimeout = 10 * time.Second
+)
+
+// PrometheusEndpoint is a Prometheus-compatible endpoint found in the cluster.
+type PrometheusEndpoint struct {
+	// Kind describes the endpoint, e.g. Prometheus Operator or VictoriaMetrics vmselect.
+	Kind string
+	// Service is the service reference of Options.PrometheusService.
+	Service string
+}
+
+// discoveryCandidate are the services of a kind of Prometheus-compatible endpoint.
+type discoveryCandidate struct {
+	kind     string
+	selector string
+	// path is the prefix of the Prometheus API
+	path string
+	// ports are the preferred port names and numbers, the first port of the service otherwise
+	ports []string
+}
+
+// discoveryCandidates are preferred in order after the Prometheus Operator instances.
+var discoveryCandidates = []discoveryCandidate{
+	{kind: "Prometheus", selector: "app.kubernetes.io/name=prometheus", ports: []string{"web", "http", "http-web", "9090"}},
+	{kind: "VictoriaMetrics vmselect", selector: "app.kubernetes.io/name=vmselect", path: "/select/0/prometheus", ports: []string{"http", "8481"}},
+	{kind: "VictoriaMetrics vmsingle", selector: "app.kubernetes.io/name=vmsingle", ports: []string{"http", "8429"}},
+	{kind: "VictoriaMetrics single", selector: "app.kubernetes.io/name=victoria-metrics-single", ports: []string{"http", "8428"}},
+	{kind: "Thanos Query", selector: "app.kubernetes.io/name=thanos-query", ports: []string{"http", "9090", "10902"}},
+	{kind: "Thanos Query", selector: "app.kubernetes.io/name=thanos,app.kubernetes.io/component=query", ports: []string{"http", "9090", "10902"}},
+}
+
+// prometheusList is the part of the Prometheus Operator Prometheus list with the instance namespaces.
+type prometheusList struct {
+	Items []struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	} `json:"items"`
+}
+
+// discoveredService is a service found by the discovery, probed before it is used.
+type discoveredService struct {
+	endpoint  PrometheusEndpoint
+	namespace string
+	name      string
+	port      string
+	path      string
+}
+
+// DiscoverPrometheus finds a Prometheus-compatible endpoint in the cluster which answers queries through
+// the API server service proxy. It looks for the Prometheus Operator Prometheus instances, the services labeled
+// app.kubernetes.io/name=prometheus, VictoriaMetrics vmselect and vmsingle, and Thanos Query, and returns
+// the first one in this order which answers. The candidates are probed in parallel within discoveryTimeout.
+// It returns nil when no endpoint is found; candidates the credentials cannot list or reach are skipped.
+func DiscoverPrometheus(ctx context.Context, config *rest.Config) (*PrometheusEndpoint, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	candidates := discoverServices(ctx, clientset)
+	answered := make([]bool, len(candidates))
+
+	var wg sync.WaitGroup
+
+	for i, c := range candidates {
+		wg.Go(func() {
+			answered[i] = probePrometheus(ctx, clientset, c.namespace, c.name, c.port, c.path)
+		})
+	}
+
+	wg.Wait()
+
+	if i := slices.Index(answered, true); i >= 0 {
+		return &candidates[i].endpoint, nil
+	}
+
+	return nil, nil
+}
+
+// discoverServices returns the candidate services in the order of preference.
+func discoverServices(ctx context.Context, clientset kubernetes.Interface) []discoveredService {
+	var candidates []discoveredService
+
+	if restClient := clientset.Discovery().RESTClient(); restClient != nil {
+		data, err := restClient.Get().AbsPath("/apis/monitoring.coreos.com/v1/prometheuses").DoRaw(ctx)
+		if err == nil {
+			var list prometheusList
+			if err := json.Unmarshal(data, &list); err == nil {
+				var namespaces []string
+
+				for _, p := range list.Items {
+					if !slices.Contains(namespaces, p.Metadata.Namespace) {
+						namespaces = append(namespaces, p.Metadata.Namespace)
+					}
+				}
+
+				for _, namespace := range namespaces {
+					candidates = append(candidates, discoveredService{
+						endpoint: PrometheusEndpoint{
+							Kind:    "Prometheus Operator",
+							Service: fmt.Sprintf("%s/%s:web", namespace, prometheusOperatorService),
+						},
+						namespace: namespace,
+						name:      prometheusOperatorService,
+						port:      "web",
+					})
+				}
+			}
+		}
+	}
+
+	for _, c := range discoveryCandidates {
+		services, err := clientset.CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: c.selector})
+		if err != nil {
+			continue
+		}
+
+		for _, svc := range services.Items {
+			port := servicePort(&svc, c.ports)
+			if port == "" {
+				continue
+			}
+
+			candidates = append(candidates, discoveredService{
+				endpoint: PrometheusEndpoint{
+					Kind:    c.kind,
+					Service: fmt.Sprintf("%s/%s:%s%s", svc.Namespace, svc.Name, port, c.path),
+				},
+				namespace: svc.Namespace,
+				name:      svc.Name,
+				port:      port,
+				path:      c.path,
+			})
+		}
+	}
+
+	return candidates
+}
+
+// servicePort returns the first preferred port of the service, or its first port.
+func servicePort(svc *corev1.Service, preferred []string) string {
+	if len(svc.Spec.Ports) == 0 {
+		return ""
+	}
+
+	for _, name := range preferred {
+		for _, p := range svc.Spec.Ports {
+			if p.Name == name || strconv.Itoa(int(p.Port)) == name {
+				return name
+			}
+		}
+	}
+
+	if svc.Spec.Ports[0].Name != "" {
+		return svc.Spec.Ports[0].Name
+	}
+
+	return strconv.Itoa(int(svc.Spec.Ports[0].Port))
+}
+
+// probePrometheus checks that the service answers an instant query through the API server service proxy.
+func probePrometheus(ctx context.Context, clientset kubernetes.Interface, namespace, name, port, path string) bool {
+	data, err := clientset.CoreV1().Services(namespace).
+		ProxyGet("", name, port, path+"/api/v1/query", map[string]string{"query": "1"}).
+		DoRaw(ctx)
+	if err != nil {
+		return false
+	}
+
+	var resp struct {
+		Status string `json:"status"`
+	}
+
+	return json.Unmarshal(data, &resp) == nil && resp.Status == "success"
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewSigV4RoundTripper exposes newSigV4RoundTripper to the tests, signing at the fixed time.
+func NewSigV4RoundTripper(opts SigV4Options, rt http.RoundTripper, now time.Time) (http.RoundTripper, error) {
+	signer, err := newSigV4RoundTripper(opts, rt)
+	if err != nil {
+		return nil, err
+	}
+
+	signer.(*sigV4RoundTripper).now = func() time.Time { return now }
+
+	return signer, nil
+}
+
+// NewKubeletProvider returns the kubelet source on the clientset, waiting interval between the cAdvisor scrapes.
+func NewKubeletProvider(clientset kubernetes.Interface, interval time.Duration) Provider {
+	return &kubeletProvider{clientset: clientset, scrapeInterval: interval, retryInterval: interval}
+}
+
+// SetSigV4Clock signs the requests of the SigV4 round trippers created by the test at the fixed time.
+func SetSigV4Clock(t *testing.T, now time.Time) {
+	t.Cleanup(func() { sigV4Now = time.Now })
+
+	sigV4Now = func() time.Time { return now }
+}
+
+// CPUCounter is a cAdvisor CPU usage counter of a container, for the tests of the kubelet source.
+type CPUCounter struct {
+	Seconds float64
+	Time    int64 // milliseconds
+}
+
+// ParseCadvisorCPU exposes parseCadvisorCPU to the tests.
+func ParseCadvisorCPU(data, namespace string, now int64) map[string]map[string]CPUCounter {
+	var counters map[string]map[string]CPUCounter
+
+	for pod, containers := range parseCadvisorCPU([]byte(data), namespace, model.Time(now)) {
+		if counters == nil {
+			counters = map[string]map[string]CPUCounter{}
+		}
+
+		counters[pod] = map[string]CPUCounter{}
+
+		for container, c := range containers {
+			counters[pod][container] = CPUCounter{Seconds: c.seconds, Time: int64(c.time)}
+		}
+	}
+
+	return counters
+}
+
+// CPURate exposes cpuRate to the tests.
+func CPURate(previous, current CPUCounter) (int64, bool) {
+	return cpuRate(
+		cpuCounter{seconds: previous.Seconds, time: model.Time(previous.Time)},
+		cpuCounter{seconds: current.Seconds, time: model.Time(current.Time)},
+	)
+}
+
+// ParseStatsSummary exposes parseStatsSummary to the tests.
+func ParseStatsSummary(data, namespace string) (map[string]map[string]int64, error) {
+	return parseStatsSummary([]byte(data), namespace)
+}
+
+// SeriesStatistic exposes seriesStatistic to the tests, on the CPU of the samples.
+func SeriesStatistic(values []int64, stat string) (int64, bool) {
+	samples := make([]sample, 0, len(values))
+	for _, v := range values {
+		samples = append(samples, sample{cpu: v})
+	}
+
+	return seriesStatistic(samples, stat, sampleCPU)
+}
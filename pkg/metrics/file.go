@@ -0,0 +1,241 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/rest"
+)
+
+// fileUsageKey is the statistic of the aggregated usage in a metrics file,
+// without it the usage is the statistic of the aggregation function.
+const fileUsageKey = "usage"
+
+// FileRecord is the usage of a container in a metrics file.
+// Statistics are keyed by name, e.g. "avg", "max" or "p95", and "usage" for the aggregated usage.
+// Empty namespace and kind match any namespace and kind.
+type FileRecord struct {
+	Namespace string                       `json:"namespace,omitempty"`
+	Kind      string                       `json:"kind,omitempty"`
+	Workload  string                       `json:"workload"`
+	Container string                       `json:"container"`
+	CPU       map[string]resource.Quantity `json:"cpu,omitempty"`
+	Memory    map[string]resource.Quantity `json:"memory,omitempty"`
+}
+
+// fileProvider reads the usage exported from another monitoring system from a CSV or JSON file.
+type fileProvider struct {
+	records     []FileRecord
+	aggregation string
+}
+
+func newFileProvider(opts Options, _ *rest.Config) (Provider, error) {
+	if opts.MetricsFile == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(opts.MetricsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics file: %w", err)
+	}
+
+	var records []FileRecord
+
+	switch {
+	case strings.EqualFold(filepath.Ext(opts.MetricsFile), ".csv"):
+		records, err = parseCSVRecords(data)
+	case strings.EqualFold(filepath.Ext(opts.MetricsFile), ".json"), bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")):
+		err = json.Unmarshal(data, &records)
+	default:
+		records, err = parseCSVRecords(data)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metrics file %s: %w", opts.MetricsFile, err)
+	}
+
+	for i, r := range records {
+		if r.Workload == "" || r.Container == "" {
+			return nil, fmt.Errorf("metrics file %s: record %d: workload and container are required", opts.MetricsFile, i+1)
+		}
+
+		if err := validateFileStatistics(r); err != nil {
+			return nil, fmt.Errorf("metrics file %s: record %d: %w", opts.MetricsFile, i+1, err)
+		}
+	}
+
+	return &fileProvider{records: records, aggregation: opts.Aggregation}, nil
+}
+
+// Name returns the name of the provider.
+func (m *fileProvider) Name() string {
+	return SourceFile
+}
+
+// GetContainerUsage returns the usage and statistics of the container record.
+func (m *fileProvider) GetContainerUsage(_ context.Context, namespace string, res resources.ResourceInfo) resources.ContainerUsage {
+	for _, r := range m.records {
+		if r.Workload != res.Name || r.Container != res.Container ||
+			(r.Namespace != "" && r.Namespace != namespace) || (r.Kind != "" && r.Kind != res.Kind) {
+			continue
+		}
+
+		usage := resources.ContainerUsage{
+			CPUStats: fileStatistics(r.CPU, (*resource.Quantity).MilliValue),
+			MemStats: fileStatistics(r.Memory, (*resource.Quantity).Value),
+		}
+
+		usage.CPU = m.usage(r.CPU, (*resource.Quantity).MilliValue)
+		usage.Mem = m.usage(r.Memory, (*resource.Quantity).Value)
+
+		return usage
+	}
+
+	return resources.ContainerUsage{}
+}
+
+// usage returns the aggregated usage of the record, the statistic of the aggregation function when it is not set.
+func (m *fileProvider) usage(values map[string]resource.Quantity, value func(*resource.Quantity) int64) int64 {
+	for _, stat := range []string{fileUsageKey, m.aggregation, resources.StatisticAvg} {
+		if q, ok := values[stat]; ok {
+			return value(&q)
+		}
+	}
+
+	return 0
+}
+
+// validateFileStatistics checks the statistic names of the record, e.g. a cpu_95 typo of cpu_p95.
+func validateFileStatistics(r FileRecord) error {
+	for _, stat := range slices.Sorted(maps.Keys(r.CPU)) {
+		if stat != fileUsageKey && !resources.ValidStatistic(stat) {
+			return fmt.Errorf("unknown cpu statistic %q", stat)
+		}
+	}
+
+	for _, stat := range slices.Sorted(maps.Keys(r.Memory)) {
+		if stat != fileUsageKey && !resources.ValidStatistic(stat) {
+			return fmt.Errorf("unknown memory statistic %q", stat)
+		}
+	}
+
+	return nil
+}
+
+func fileStatistics(values map[string]resource.Quantity, value func(*resource.Quantity) int64) map[string]int64 {
+	if len(values) == 0 {
+		return nil
+	}
+
+	stats := map[string]int64{}
+
+	for stat, q := range values {
+		if stat != fileUsageKey {
+			stats[stat] = value(&q)
+		}
+	}
+
+	return stats
+}
+
+// parseCSVRecords parses a CSV file with a header row of namespace, kind, workload and container columns,
+// and cpu_<statistic> and memory_<statistic> columns, e.g. cpu_p95 or memory_max.
+func parseCSVRecords(data []byte) ([]FileRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the header: %w", err)
+	}
+
+	var records []FileRecord
+
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		record := FileRecord{}
+
+		for i, column := range header {
+			cell := strings.TrimSpace(row[i])
+
+			switch column = strings.ToLower(strings.TrimSpace(column)); column {
+			case "namespace":
+				record.Namespace = cell
+			case "kind":
+				record.Kind = cell
+			case "workload":
+				record.Workload = cell
+			case "container":
+				record.Container = cell
+			default:
+				if cell == "" {
+					continue
+				}
+
+				q, err := resource.ParseQuantity(cell)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: column %s: %w", line, column, err)
+				}
+
+				if stat, ok := strings.CutPrefix(column, "cpu_"); ok {
+					record.CPU = setQuantity(record.CPU, stat, q)
+				} else if stat, ok := strings.CutPrefix(column, "memory_"); ok {
+					record.Memory = setQuantity(record.Memory, stat, q)
+				} else {
+					return nil, fmt.Errorf("line %d: unknown column %s", line, column)
+				}
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func setQuantity(values map[string]resource.Quantity, stat string, q resource.Quantity) map[string]resource.Quantity {
+	if values == nil {
+		values = map[string]resource.Quantity{}
+	}
+
+	values[stat] = q
+
+	return values
+}
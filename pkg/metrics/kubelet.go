@@ -0,0 +1,327 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// kubeletScrapeInterval is the gap between the two cAdvisor scrapes the CPU rate is computed from,
+	// longer than the 10-15s housekeeping interval cAdvisor refreshes the counters at.
+	kubeletScrapeInterval = 20 * time.Second
+	// kubeletScrapeRetries is the number of extra scrapes of the nodes with counters which have not moved yet.
+	kubeletScrapeRetries = 3
+	// kubeletRetryInterval is the interval of the extra scrapes.
+	kubeletRetryInterval = 5 * time.Second
+
+	cadvisorCPUUsage = "container_cpu_usage_seconds_total"
+)
+
+// kubeletProvider reads the current usage from the kubelets through the API server node proxy.
+type kubeletProvider struct {
+	clientset kubernetes.Interface
+	// scrapeInterval and retryInterval are the waits between the cAdvisor scrapes
+	scrapeInterval time.Duration
+	retryInterval  time.Duration
+	// usage of the namespace containers, collected once
+	kubeletUsage     podSamples
+	kubeletNamespace string
+}
+
+func newKubeletProvider(_ Options, config *rest.Config) (Provider, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kubeletProvider{
+		clientset:      clientset,
+		scrapeInterval: kubeletScrapeInterval,
+		retryInterval:  kubeletRetryInterval,
+	}, nil
+}
+
+// Name returns the name of the provider.
+func (m *kubeletProvider) Name() string {
+	return SourceKubelet
+}
+
+// cpuCounter is a cAdvisor CPU usage counter of a container.
+type cpuCounter struct {
+	seconds float64
+	time    model.Time
+}
+
+// GetContainerUsage returns the usage of the container from the kubelets hosting the namespace pods.
+func (m *kubeletProvider) GetContainerUsage(ctx context.Context, namespace string, res resources.ResourceInfo) resources.ContainerUsage {
+	var usage resources.ContainerUsage
+
+	samples := m.collectKubeletUsage(ctx, namespace)
+
+	for _, podName := range slices.Sorted(maps.Keys(samples)) {
+		if !resources.PodBelongsToWorkload(podName, res.Kind, res.Name) {
+			continue
+		}
+
+		if values := samples[podName][res.Container]; len(values) > 0 {
+			usage.Pods = append(usage.Pods, resources.PodUsage{Pod: podName, CPU: values[0].cpu, Mem: values[0].mem})
+		}
+	}
+
+	usage.CPU, usage.Mem = averageUsage(usage.Pods)
+
+	return usage
+}
+
+// collectKubeletUsage reads the usage of all containers in the namespace through the API server node proxy.
+// The CPU usage is the rate between two cAdvisor scrapes, the memory usage is the working set of the stats summary.
+// Containers without a CPU rate, e.g. when cAdvisor has not refreshed the counter, have no CPU usage.
+// The usage is collected once per namespace.
+func (m *kubeletProvider) collectKubeletUsage(ctx context.Context, namespace string) podSamples {
+	if m.kubeletUsage != nil && m.kubeletNamespace == namespace {
+		return m.kubeletUsage
+	}
+
+	usage := podSamples{}
+
+	m.kubeletUsage, m.kubeletNamespace = usage, namespace
+
+	pods, err := m.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return usage
+	}
+
+	var nodes []string
+
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" && !slices.Contains(nodes, pod.Spec.NodeName) {
+			nodes = append(nodes, pod.Spec.NodeName)
+		}
+	}
+
+	first := map[string]map[string]map[string]cpuCounter{}
+
+	for _, node := range nodes {
+		if counters := m.scrapeCadvisor(ctx, node, namespace); len(counters) > 0 {
+			first[node] = counters
+		}
+	}
+
+	// Without counters, e.g. when nodes/proxy is not allowed, there is nothing to wait for.
+	if len(first) == 0 {
+		return usage
+	}
+
+	pending := slices.Sorted(maps.Keys(first))
+	wait := m.scrapeInterval
+
+	for attempt := 0; attempt <= kubeletScrapeRetries && len(pending) > 0; attempt++ {
+		select {
+		case <-ctx.Done():
+			return usage
+		case <-time.After(wait):
+		}
+
+		var next []string
+
+		for _, node := range pending {
+			moved := true
+
+			for podName, containers := range m.scrapeCadvisor(ctx, node, namespace) {
+				for container, counter := range containers {
+					previous, ok := first[node][podName][container]
+					if !ok {
+						continue
+					}
+
+					cpu, ok := cpuRate(previous, counter)
+					if !ok {
+						moved = false
+
+						continue
+					}
+
+					usage.set(podName, container, func(s *sample) { s.cpu = cpu })
+				}
+			}
+
+			if !moved {
+				next = append(next, node)
+			}
+		}
+
+		pending, wait = next, m.retryInterval
+	}
+
+	for node := range first {
+		data, err := m.clientset.CoreV1().RESTClient().Get().
+			AbsPath("/api/v1/nodes", node, "proxy", "stats", "summary").
+			DoRaw(ctx)
+		if err != nil {
+			continue
+		}
+
+		memory, err := parseStatsSummary(data, namespace)
+		if err != nil {
+			continue
+		}
+
+		for podName, containers := range memory {
+			for container, mem := range containers {
+				usage.set(podName, container, func(s *sample) { s.mem = mem })
+			}
+		}
+	}
+
+	return usage
+}
+
+// scrapeCadvisor returns the CPU usage counters of the namespace containers from the cAdvisor metrics of the node,
+// keyed by pod and container name.
+func (m *kubeletProvider) scrapeCadvisor(ctx context.Context, node, namespace string) map[string]map[string]cpuCounter {
+	data, err := m.clientset.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/nodes", node, "proxy", "metrics", "cadvisor").
+		DoRaw(ctx)
+	if err != nil {
+		return nil
+	}
+
+	return parseCadvisorCPU(data, namespace, model.Now())
+}
+
+// parseCadvisorCPU returns the CPU usage counters of the namespace containers from the cAdvisor text metrics,
+// keyed by pod and container name. Samples without a timestamp are stamped with now.
+func parseCadvisorCPU(data []byte, namespace string, now model.Time) map[string]map[string]cpuCounter {
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(data))
+	if err != nil || families[cadvisorCPUUsage] == nil {
+		return nil
+	}
+
+	vector, err := expfmt.ExtractSamples(&expfmt.DecodeOptions{Timestamp: now}, families[cadvisorCPUUsage])
+	if err != nil {
+		return nil
+	}
+
+	counters := map[string]map[string]cpuCounter{}
+
+	for _, s := range vector {
+		podName, container := string(s.Metric["pod"]), string(s.Metric["container"])
+		if string(s.Metric["namespace"]) != namespace || podName == "" || container == "" || container == "POD" {
+			continue
+		}
+
+		if counters[podName] == nil {
+			counters[podName] = map[string]cpuCounter{}
+		}
+
+		counters[podName][container] = cpuCounter{seconds: float64(s.Value), time: s.Timestamp}
+	}
+
+	return counters
+}
+
+// cpuRate returns the CPU usage in millicores between two counters of a container.
+// It reports no rate when the counter has not moved, as cAdvisor serves the same value until its next refresh,
+// or was reset by a container restart.
+func cpuRate(previous, current cpuCounter) (int64, bool) {
+	if current.time <= previous.time || current.seconds <= previous.seconds {
+		return 0, false
+	}
+
+	return int64((current.seconds - previous.seconds) / current.time.Sub(previous.time).Seconds() * 1000), true
+}
+
+// kubeletSummary is the part of the kubelet stats summary (/stats/summary) with the container memory usage.
+type kubeletSummary struct {
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		Containers []struct {
+			Name   string `json:"name"`
+			Memory *struct {
+				WorkingSetBytes *uint64 `json:"workingSetBytes"`
+			} `json:"memory"`
+		} `json:"containers"`
+	} `json:"pods"`
+}
+
+// parseStatsSummary returns the memory working set of the namespace containers from the kubelet stats summary,
+// keyed by pod and container name.
+func parseStatsSummary(data []byte, namespace string) (map[string]map[string]int64, error) {
+	var summary kubeletSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, err
+	}
+
+	memory := map[string]map[string]int64{}
+
+	for _, pod := range summary.Pods {
+		if pod.PodRef.Namespace != namespace {
+			continue
+		}
+
+		for _, c := range pod.Containers {
+			if c.Memory == nil || c.Memory.WorkingSetBytes == nil {
+				continue
+			}
+
+			if memory[pod.PodRef.Name] == nil {
+				memory[pod.PodRef.Name] = map[string]int64{}
+			}
+
+			memory[pod.PodRef.Name][c.Name] = int64(*c.Memory.WorkingSetBytes)
+		}
+	}
+
+	return memory, nil
+}
+
+// set updates the single sample of the container.
+func (p podSamples) set(pod, container string, update func(*sample)) {
+	if p[pod] == nil {
+		p[pod] = map[string][]sample{}
+	}
+
+	if len(p[pod][container]) == 0 {
+		p[pod][container] = []sample{{}}
+	}
+
+	update(&p[pod][container][0])
+}
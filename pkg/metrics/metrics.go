@@ -20,232 +20,206 @@ package metrics
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"io"
 	"strings"
 	"time"
 
-	"github.com/prometheus/client_golang/api"
-	v1prometheus "github.com/prometheus/client_golang/api/prometheus/v1"
-	"github.com/prometheus/common/model"
-
 	"github.com/sergelogvinov/helm-resources/pkg/resources"
 
-	v1 "k8s.io/api/core/v1"
-	vpa "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
 	"k8s.io/client-go/rest"
-	metricsv1 "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
-// Client provides methods to retrieve resource usage metrics for Kubernetes workloads.
+// Provider provides the usage of containers over the metrics window.
+type Provider interface {
+	// Name returns the name of the provider, it is reported as the metrics source of the usage.
+	Name() string
+	// GetContainerUsage returns the usage of the container, zero CPU and memory usage means the provider has none.
+	// CPU values are in millicores and memory values in bytes.
+	GetContainerUsage(ctx context.Context, namespace string, res resources.ResourceInfo) resources.ContainerUsage
+}
+
+// Client provides the usage of containers from a chain of providers, the first provider returning usage wins.
+// Client is a Provider itself.
 type Client struct {
-	vpaClient        vpa.Interface
-	prometheusClient v1prometheus.API
-	metricsClient    metricsv1.Interface
-	metricsWindow    string
-	aggregation      string
+	providers []Provider
+	// vpa attaches the VPA recommendation to the usage of every provider
+	vpa *vpaProvider
+	// metricsServer collects the samples of Sample
+	metricsServer *metricsServerProvider
 }
 
-// New creates a new Client with the provided clients and configuration.
-// All parameters are optional; pass nil for clients you don't want to use.
-// metricsWindow specifies the time window for Prometheus queries (e.g., "5m", "1h").
-// aggregation specifies the aggregation function for Prometheus queries ("avg" or "max").
-func New(
-	prometheusURL string,
-	metricsWindow string,
-	aggregation string,
-	config *rest.Config,
-) (*Client, error) {
-	var (
-		prometheusClient v1prometheus.API
-		vpaClient        vpa.Interface
-		metricsClient    metricsv1.Interface
-	)
-
-	if prometheusURL != "" {
-		promClient, err := api.NewClient(api.Config{
-			Address: prometheusURL,
-			RoundTripper: &http.Transport{
-				IdleConnTimeout: 30 * time.Second,
-			},
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to create Prometheus client: %w", err)
-		}
+// Options configures the metrics sources of the Client.
+type Options struct {
+	// PrometheusURL is the Prometheus server URL, Prometheus is not used if it and PrometheusService are empty.
+	PrometheusURL string
+	// PrometheusService is the namespace/name[:port][/path] reference of the in-cluster Prometheus service,
+	// reached through the API server service proxy, see ParsePrometheusService.
+	PrometheusService string
+	// Prometheus configures the authentication, TLS and headers of the Prometheus connection.
+	Prometheus PrometheusOptions
+	// OpenCostURL is the OpenCost API URL, OpenCost is not used if empty.
+	OpenCostURL string
+	// MetricsFile is the CSV or JSON file of the file source, the file source is not used if empty.
+	MetricsFile string
+	// Sources are the metrics sources in fallback order, see ParseSources. Empty means SourceAuto.
+	Sources []string
+	// MetricsWindow specifies the time window for Prometheus queries (e.g., "5m", "1h").
+	MetricsWindow string
+	// Aggregation specifies the aggregation function for Prometheus queries ("avg" or "max").
+	Aggregation string
+	// SampleInterval is the interval of metrics-server samples over the metrics window, see Client.Sample.
+	SampleInterval time.Duration
+	// Statistics are the usage statistics to collect in addition to the aggregated usage, e.g. "p95".
+	Statistics []string
+	// Warnings receives the errors of the sources which are skipped, nil discards them.
+	Warnings io.Writer
+}
 
-		prometheusClient = v1prometheus.NewAPI(promClient)
+// New creates a new Client with the providers of the metrics sources, see Register.
+// The Kubernetes configuration is optional; pass nil to skip VPA, metrics-server and kubelet sources.
+// With several sources, a source which fails to initialize is skipped with a warning and the others are used.
+func New(opts Options, config *rest.Config) (*Client, error) {
+	if opts.Aggregation != "avg" && opts.Aggregation != "max" {
+		opts.Aggregation = "avg" // default fallback
 	}
 
-	if config != nil {
-		vpaClientset, err := vpa.NewForConfig(config)
-		if err == nil {
-			vpaClient = vpaClientset
-		}
+	sources := opts.Sources
+	if len(sources) == 0 {
+		sources, _ = ParseSources(SourceAuto) //nolint:errcheck
 	}
 
-	if config != nil {
-		metricsClientset, err := metricsv1.NewForConfig(config)
-		if err == nil {
-			metricsClient = metricsClientset
+	client := &Client{}
+
+	for _, source := range sources {
+		factory, ok := registry[source]
+		if !ok {
+			return nil, fmt.Errorf("unknown metrics source %q", source)
 		}
-	}
 
-	if aggregation != "avg" && aggregation != "max" {
-		aggregation = "avg" // default fallback
-	}
+		provider, err := factory(opts, config)
+		if err != nil {
+			if len(sources) == 1 {
+				return nil, fmt.Errorf("failed to create %s metrics provider: %w", source, err)
+			}
 
-	return &Client{
-		vpaClient:        vpaClient,
-		prometheusClient: prometheusClient,
-		metricsClient:    metricsClient,
-		metricsWindow:    metricsWindow,
-		aggregation:      aggregation,
-	}, nil
-}
+			if opts.Warnings != nil {
+				fmt.Fprintf(opts.Warnings, "Warning: skipping the %s metrics source: %v\n", source, err)
+			}
 
-// GetContainerMetrics retrieves CPU and memory usage for a container using the configured metrics source.
-// Returns CPU in millicores and memory in bytes.
-func (m *Client) GetContainerMetrics(
-	ctx context.Context,
-	namespace string,
-	res resources.ResourceInfo,
-) (int64, int64) {
-	if m.prometheusClient != nil {
-		return m.getPrometheusMetrics(ctx, namespace, res)
-	}
+			continue
+		}
 
-	var (
-		cpu int64
-		mem int64
-	)
+		if provider == nil {
+			continue
+		}
 
-	if m.vpaClient != nil && cpu == 0 && mem == 0 {
-		cpu, mem = m.getVPAMetrics(ctx, namespace, res)
-	}
+		switch p := provider.(type) {
+		case *metricsServerProvider:
+			client.metricsServer = p
+		case *vpaProvider:
+			// The VPA source shares the VPA list of the recommendations.
+			client.vpa = p
+		}
 
-	if m.metricsClient != nil {
-		cpu, mem = m.getKubernetesMetrics(ctx, namespace, res)
+		client.providers = append(client.providers, provider)
 	}
 
-	return cpu, mem
-}
-
-// getPrometheusMetrics retrieves CPU and memory usage from Prometheus
-func (m *Client) getPrometheusMetrics(ctx context.Context, namespace string, res resources.ResourceInfo) (int64, int64) {
-	cpuQuery := fmt.Sprintf(`%s(rate(container_cpu_usage_seconds_total{namespace="%s",pod=~"%s.*",container="%s"}[%s])) * 1000`, m.aggregation, namespace, res.Name, res.Container, m.metricsWindow)
-
-	cpuResult, _, err := m.prometheusClient.Query(ctx, cpuQuery, time.Now())
-	if err != nil {
-		return 0, 0
+	if len(opts.Sources) == 1 && len(client.providers) == 0 {
+		return nil, fmt.Errorf("metrics source %s is not available", opts.Sources[0])
 	}
 
-	memQuery := fmt.Sprintf(`%s(container_memory_usage_bytes{namespace="%s",pod=~"%s.*",container="%s"}[%s])`, m.aggregation, namespace, res.Name, res.Container, m.metricsWindow)
-
-	memResult, _, err := m.prometheusClient.Query(ctx, memQuery, time.Now())
-	if err != nil {
-		return 0, 0
+	if client.vpa == nil && config != nil {
+		if provider, err := newVPA(config); err == nil {
+			client.vpa = provider
+		}
 	}
 
-	var cpuUsage, memUsage int64
+	return client, nil
+}
 
-	if cpuVector, ok := cpuResult.(model.Vector); ok && len(cpuVector) > 0 {
-		cpuUsage = int64(cpuVector[0].Value)
-	}
+// NewWithProviders creates a new Client from the providers in fallback order.
+func NewWithProviders(providers ...Provider) *Client {
+	return &Client{providers: providers}
+}
 
-	if memVector, ok := memResult.(model.Vector); ok && len(memVector) > 0 {
-		memUsage = int64(memVector[0].Value)
+// Name returns the names of the providers in fallback order.
+func (m *Client) Name() string {
+	names := make([]string, 0, len(m.providers))
+	for _, p := range m.providers {
+		names = append(names, p.Name())
 	}
 
-	return cpuUsage, memUsage
+	return strings.Join(names, ",")
 }
 
-// getKubernetesMetrics retrieves CPU and Memory usage for a container from the
-// Kubernetes Metrics API (metrics.k8s.io/v1).
-func (m *Client) getKubernetesMetrics(ctx context.Context, namespace string, res resources.ResourceInfo) (int64, int64) {
-	podMetricsList, err := m.metricsClient.MetricsV1beta1().PodMetricses(namespace).List(ctx, resources.ListOptions(res.Labels))
-	if err != nil {
-		return 0, 0
-	}
-
-	var (
-		totalCPU int64
-		totalMem int64
-		count    int
-	)
+// GetContainerUsage retrieves the usage of a container from the first provider returning it.
+// CPU values are in millicores and memory values in bytes.
+func (m *Client) GetContainerUsage(
+	ctx context.Context,
+	namespace string,
+	res resources.ResourceInfo,
+) resources.ContainerUsage {
+	var vpaRecommendation *resources.VPARecommendation
 
-	for _, podMetrics := range podMetricsList.Items {
-		podName := podMetrics.Name
+	if m.vpa != nil {
+		vpaRecommendation = m.vpa.getVPARecommendation(ctx, namespace, res)
+	}
 
-		if !m.podBelongsToWorkload(podName, res.Kind, res.Name) {
+	for _, provider := range m.providers {
+		usage := provider.GetContainerUsage(ctx, namespace, res)
+		if usage.CPU == 0 && usage.Mem == 0 {
 			continue
 		}
 
-		for _, containerMetrics := range podMetrics.Containers {
-			if containerMetrics.Name != res.Container {
-				continue
-			}
+		if usage.Source == "" {
+			usage.Source = provider.Name()
+		}
 
-			if cpu, ok := containerMetrics.Usage[v1.ResourceCPU]; ok {
-				totalCPU += cpu.MilliValue()
-			}
+		if usage.VPA == nil {
+			usage.VPA = vpaRecommendation
+		}
 
-			if mem, ok := containerMetrics.Usage[v1.ResourceMemory]; ok {
-				totalMem += mem.Value()
-			}
+		resources.MarkOutliers(usage.Pods)
 
-			count++
-		}
+		return usage
 	}
 
-	if count == 0 {
-		return 0, 0
-	}
+	return resources.ContainerUsage{VPA: vpaRecommendation}
+}
+
+// GetContainerMetrics retrieves CPU and memory usage for a container from the first provider returning it.
+// Returns CPU in millicores and memory in bytes.
+func (m *Client) GetContainerMetrics(
+	ctx context.Context,
+	namespace string,
+	res resources.ResourceInfo,
+) (int64, int64) {
+	usage := m.GetContainerUsage(ctx, namespace, res)
 
-	return totalCPU / int64(count), totalMem / int64(count)
+	return usage.CPU, usage.Mem
 }
 
-// getVPAMetrics retrieves CPU and memory recommendations from VPA
-func (m *Client) getVPAMetrics(ctx context.Context, namespace string, res resources.ResourceInfo) (int64, int64) {
-	vpaList, err := m.vpaClient.AutoscalingV1().VerticalPodAutoscalers(namespace).List(ctx, resources.ListOptions(res.Labels))
-	if err != nil {
-		return 0, 0
+// Sample collects metrics-server samples over the metrics window, see metricsServerProvider.Sample.
+func (m *Client) Sample(ctx context.Context, namespace string, progress io.Writer) error {
+	if m.metricsServer == nil {
+		return fmt.Errorf("sampling requires the %s metrics source", SourceMetricsServer)
 	}
 
-	for _, vpaItem := range vpaList.Items {
-		if vpaItem.Spec.TargetRef != nil && vpaItem.Spec.TargetRef.Name == res.Name && vpaItem.Spec.TargetRef.Kind == res.Kind {
-			if vpaItem.Status.Recommendation != nil {
-				for _, containerMetrics := range vpaItem.Status.Recommendation.ContainerRecommendations {
-					if containerMetrics.ContainerName == res.Container {
-						var cpuUsage, memUsage int64
-
-						if containerMetrics.Target != nil {
-							if cpu, ok := containerMetrics.Target[v1.ResourceCPU]; ok {
-								cpuUsage = cpu.MilliValue()
-							}
-
-							if mem, ok := containerMetrics.Target[v1.ResourceMemory]; ok {
-								memUsage = mem.Value()
-							}
-						}
-
-						return cpuUsage, memUsage
-					}
-				}
-			}
-		}
+	return m.metricsServer.Sample(ctx, namespace, progress)
+}
+
+// averageUsage returns the average CPU and memory usage across pods.
+func averageUsage(pods []resources.PodUsage) (int64, int64) {
+	if len(pods) == 0 {
+		return 0, 0
 	}
 
-	return 0, 0
-}
+	var totalCPU, totalMem int64
 
-// podBelongsToWorkload reports whether a pod name belongs to the given workload.
-func (m *Client) podBelongsToWorkload(podName, kind, workloadName string) bool {
-	switch kind {
-	case "StatefulSet":
-		// StatefulSet pods are named "<workload>-<ordinal>".
-		return podName == workloadName || (len(podName) > len(workloadName)+1 && strings.HasPrefix(podName, workloadName+"-"))
-	default:
-		// Deployment, DaemonSet, CronJob/Job pods are named "<workload>-<hash>-<rand>"
-		// or "<workload>-<rand>". Match by prefix.
-		return podName == workloadName || (len(podName) > len(workloadName)+1 && strings.HasPrefix(podName, workloadName+"-"))
+	for _, p := range pods {
+		totalCPU += p.CPU
+		totalMem += p.Mem
 	}
+
+	return totalCPU / int64(len(pods)), totalMem / int64(len(pods))
 }
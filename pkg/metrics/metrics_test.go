@@ -0,0 +1,1166 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics_test
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergelogvinov/helm-resources/pkg/metrics"
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+type fakeProvider struct {
+	name  string
+	usage map[string]resources.ContainerUsage
+}
+
+func (f fakeProvider) Name() string {
+	return f.name
+}
+
+func (f fakeProvider) GetContainerUsage(_ context.Context, _ string, res resources.ResourceInfo) resources.ContainerUsage {
+	return f.usage[res.Container]
+}
+
+func TestClientFallback(t *testing.T) {
+	client := metrics.NewWithProviders(
+		fakeProvider{name: "primary", usage: map[string]resources.ContainerUsage{
+			"app": {CPU: 100, Mem: 256},
+		}},
+		fakeProvider{name: "secondary", usage: map[string]resources.ContainerUsage{
+			"app":     {CPU: 200, Mem: 512},
+			"sidecar": {CPU: 10, Mem: 32},
+		}},
+	)
+
+	tests := []struct {
+		name      string
+		container string
+		expect    resources.ContainerUsage
+	}{
+		{
+			name:      "first provider",
+			container: "app",
+			expect:    resources.ContainerUsage{Source: "primary", CPU: 100, Mem: 256},
+		},
+		{
+			name:      "fallback provider",
+			container: "sidecar",
+			expect:    resources.ContainerUsage{Source: "secondary", CPU: 10, Mem: 32},
+		},
+		{
+			name:      "no usage",
+			container: "init",
+			expect:    resources.ContainerUsage{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := client.GetContainerUsage(t.Context(), "default", resources.ResourceInfo{Container: tt.container})
+
+			assert.Equal(t, tt.expect, usage)
+		})
+	}
+
+	assert.Equal(t, "primary,secondary", client.Name())
+}
+
+func TestParseSources(t *testing.T) {
+	sources, err := metrics.ParseSources("auto")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{metrics.SourceFile, metrics.SourcePrometheus, metrics.SourceOpenCost, metrics.SourceMetricsServer, metrics.SourceVPA}, sources)
+
+	sources, err = metrics.ParseSources("vpa, prometheus,vpa")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{metrics.SourceVPA, metrics.SourcePrometheus}, sources)
+
+	_, err = metrics.ParseSources("graphite")
+	assert.Error(t, err)
+}
+
+func TestFileProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "csv",
+			file: "usage.csv",
+			content: `namespace,kind,workload,container,cpu_avg,cpu_p95,memory_avg,memory_max
+prod,Deployment,web,web,120m,300m,200Mi,256Mi
+`,
+		},
+		{
+			name: "json",
+			file: "usage.json",
+			content: `[{"namespace": "prod", "kind": "Deployment", "workload": "web", "container": "web",
+  "cpu": {"avg": "120m", "p95": "300m"}, "memory": {"avg": "200Mi", "max": "256Mi"}}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			client, err := metrics.New(metrics.Options{MetricsFile: path, Sources: []string{metrics.SourceFile}}, nil)
+			assert.NoError(t, err)
+
+			usage := client.GetContainerUsage(t.Context(), "prod", resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: "web"})
+
+			assert.Equal(t, resources.ContainerUsage{
+				Source:   metrics.SourceFile,
+				CPU:      120,
+				Mem:      200 * 1024 * 1024,
+				CPUStats: map[string]int64{"avg": 120, "p95": 300},
+				MemStats: map[string]int64{"avg": 200 * 1024 * 1024, "max": 256 * 1024 * 1024},
+			}, usage)
+
+			usage = client.GetContainerUsage(t.Context(), "staging", resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: "web"})
+			assert.Equal(t, resources.ContainerUsage{}, usage)
+		})
+	}
+}
+
+func TestOpenCostProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/allocation/compute", r.URL.Path)
+		assert.Equal(t, "namespace,controllerKind,controller,container", r.URL.Query().Get("aggregate"))
+		assert.Equal(t, "24h", r.URL.Query().Get("window"))
+
+		fmt.Fprint(w, `{"code": 200, "data": [{
+  "prod/deployment/web/web": {
+    "properties": {"namespace": "prod", "controllerKind": "deployment", "controller": "web", "container": "web"},
+    "cpuCoreUsageAverage": 0.25, "cpuCoreUsageMax": 0.8, "ramByteUsageAverage": 268435456,
+    "cpuCost": 1.5, "ramCost": 0.5, "totalCost": 2.0
+  }
+}]}`)
+	}))
+	defer server.Close()
+
+	client, err := metrics.New(metrics.Options{OpenCostURL: server.URL, MetricsWindow: "24h", Sources: []string{metrics.SourceOpenCost}}, nil)
+	assert.NoError(t, err)
+
+	usage := client.GetContainerUsage(t.Context(), "prod", resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: "web"})
+
+	assert.Equal(t, resources.ContainerUsage{
+		Source:   metrics.SourceOpenCost,
+		CPU:      250,
+		Mem:      256 * 1024 * 1024,
+		CPUStats: map[string]int64{"avg": 250, "max": 800},
+		MemStats: map[string]int64{"avg": 256 * 1024 * 1024},
+		Cost:     &resources.Cost{Window: "24h", CPU: 1.5, Memory: 0.5, Total: 2.0},
+	}, usage)
+}
+
+func TestFileProviderInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		err     string
+	}{
+		{
+			name: "csv statistic typo",
+			file: "usage.csv",
+			content: `workload,container,cpu_95,memory_max
+web,web,300m,256Mi
+`,
+			err: `record 1: unknown cpu statistic "95"`,
+		},
+		{
+			name:    "json statistic typo",
+			file:    "usage.json",
+			content: `[{"workload": "web", "container": "web", "cpu": {"usage": "120m"}, "memory": {"maximum": "256Mi"}}]`,
+			err:     `record 1: unknown memory statistic "maximum"`,
+		},
+		{
+			name: "csv unknown column",
+			file: "usage.csv",
+			content: `workload,container,disk_max
+web,web,1Gi
+`,
+			err: "line 2: unknown column disk_max",
+		},
+		{
+			name:    "missing container",
+			file:    "usage.json",
+			content: `[{"workload": "web", "cpu": {"avg": "120m"}}]`,
+			err:     "record 1: workload and container are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			_, err := metrics.New(metrics.Options{MetricsFile: path, Sources: []string{metrics.SourceFile}}, nil)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestOpenCostWarnings(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		warning string
+	}{
+		{
+			name:    "error status",
+			status:  http.StatusInternalServerError,
+			body:    `{"code": 500, "message": "prometheus unavailable"}`,
+			warning: `Warning: failed to query the OpenCost allocations of namespace prod: unexpected status 500 Internal Server Error: {"code": 500, "message": "prometheus unavailable"}`,
+		},
+		{
+			name:    "invalid response",
+			status:  http.StatusOK,
+			body:    `{"code": 200, "data": [`,
+			warning: "Warning: failed to query the OpenCost allocations of namespace prod: failed to decode the response: unexpected end of JSON input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			var warnings strings.Builder
+
+			client, err := metrics.New(metrics.Options{
+				OpenCostURL: server.URL,
+				Sources:     []string{metrics.SourceOpenCost},
+				Warnings:    &warnings,
+			}, nil)
+			assert.NoError(t, err)
+
+			res := resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: "web"}
+			assert.Equal(t, resources.ContainerUsage{}, client.GetContainerUsage(t.Context(), "prod", res))
+			assert.Equal(t, resources.ContainerUsage{}, client.GetContainerUsage(t.Context(), "prod", res))
+			assert.Equal(t, tt.warning+"\n", warnings.String())
+		})
+	}
+}
+
+func TestPrometheusOptions(t *testing.T) {
+	partialResponse := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "team-a", r.Header.Get("X-Scope-OrgID"))
+		assert.Equal(t, "5m", r.URL.Query().Get("max_source_resolution"))
+		assert.Equal(t, "false", r.URL.Query().Get("partial_response"))
+
+		fmt.Fprint(w, `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [1700000000, "100"]}]}}`)
+	}))
+	defer server.Close()
+
+	client, err := metrics.New(metrics.Options{
+		PrometheusURL: server.URL,
+		Prometheus: metrics.PrometheusOptions{
+			BearerToken:         "secret",
+			Headers:             map[string]string{"X-Scope-OrgID": "team-a"},
+			MaxSourceResolution: "5m",
+			PartialResponse:     &partialResponse,
+		},
+		Sources:       []string{metrics.SourcePrometheus},
+		MetricsWindow: "5m",
+		Aggregation:   "avg",
+	}, nil)
+	assert.NoError(t, err)
+
+	usage := client.GetContainerUsage(t.Context(), "prod", resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: "web"})
+	assert.Equal(t, int64(100), usage.CPU)
+	assert.Equal(t, int64(100), usage.Mem)
+}
+
+func TestPrometheusOptionsValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		opts  metrics.PrometheusOptions
+		valid bool
+	}{
+		{name: "empty", valid: true},
+		{name: "bearer token", opts: metrics.PrometheusOptions{BearerTokenFile: "/token"}, valid: true},
+		{name: "token and basic auth", opts: metrics.PrometheusOptions{BearerToken: "secret", Username: "admin"}},
+		{name: "kubeconfig and basic auth", opts: metrics.PrometheusOptions{KubeconfigAuth: true, Username: "admin"}},
+		{name: "cert without key", opts: metrics.PrometheusOptions{CertFile: "/tls.crt"}},
+		{name: "resolution", opts: metrics.PrometheusOptions{MaxSourceResolution: "1h"}, valid: true},
+		{name: "invalid resolution", opts: metrics.PrometheusOptions{MaxSourceResolution: "10m"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// setAWSEnv isolates the AWS credential chain from the environment and the files of the user.
+func setAWSEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for _, name := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION",
+		"AWS_ROLE_ARN", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ENDPOINT_URL", "AWS_ENDPOINT_URL_STS",
+	} {
+		t.Setenv(name, "")
+	}
+
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+}
+
+func TestSigV4(t *testing.T) {
+	// The expected signatures are computed independently; the first one is get-vanilla of the AWS SigV4 test suite.
+	creds := map[string]string{"AWS_ACCESS_KEY_ID": "AKIDEXAMPLE", "AWS_SECRET_ACCESS_KEY": "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		token  string
+		opts   metrics.SigV4Options
+		now    time.Time
+		expect string
+	}{
+		{
+			name:   "get vanilla",
+			method: http.MethodGet,
+			url:    "https://example.amazonaws.com/",
+			opts:   metrics.SigV4Options{Region: "us-east-1", Service: "service"},
+			now:    time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC),
+			expect: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:   "escaped path and session token",
+			method: http.MethodGet,
+			url:    `https://aps-workspaces.us-west-2.amazonaws.com/workspaces/ws-1234/api/v1/label/app%20name/values?match[]=up{job="node"}`,
+			token:  "session",
+			opts:   metrics.SigV4Options{Region: "us-west-2"},
+			now:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			expect: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260101/us-west-2/aps/aws4_request, " +
+				"SignedHeaders=host;x-amz-date;x-amz-security-token, Signature=6666693262545936e0f97d2c434ca189247cd4b8b5d12b4d3ebc2f8d5547b7f5",
+		},
+		{
+			name:   "form body",
+			method: http.MethodPost,
+			url:    "https://aps-workspaces.us-west-2.amazonaws.com/workspaces/ws-1234/api/v1/query",
+			body:   "query=sum%28rate%28container_cpu_usage_seconds_total%5B5m%5D%29%29",
+			opts:   metrics.SigV4Options{Region: "us-west-2"},
+			now:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			expect: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260101/us-west-2/aps/aws4_request, " +
+				"SignedHeaders=content-length;content-type;host;x-amz-date, Signature=83bfabc98264a1466f636d8d001647fa0637b64bf3540d8b5d4c4d263112a67b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setAWSEnv(t, creds)
+			t.Setenv("AWS_SESSION_TOKEN", tt.token)
+
+			var signed *http.Request
+
+			rt, err := metrics.NewSigV4RoundTripper(tt.opts, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				signed = req
+
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			}), tt.now)
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			assert.NoError(t, err)
+
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				req.Body, req.ContentLength = nil, 0
+			}
+
+			_, err = rt.RoundTrip(req)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.expect, signed.Header.Get("Authorization"))
+			assert.Equal(t, tt.token, signed.Header.Get("X-Amz-Security-Token"))
+		})
+	}
+}
+
+func TestPrometheusSigV4(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		creds aws.Credentials
+	}{
+		{
+			name:  "environment",
+			env:   map[string]string{"AWS_ACCESS_KEY_ID": "AKIDENV", "AWS_SECRET_ACCESS_KEY": "secret", "AWS_SESSION_TOKEN": "session"},
+			creds: aws.Credentials{AccessKeyID: "AKIDENV", SecretAccessKey: "secret", SessionToken: "session"},
+		},
+		{
+			name:  "web identity",
+			env:   map[string]string{"AWS_ROLE_ARN": "arn:aws:iam::123456789012:role/prometheus", "AWS_ROLE_SESSION_NAME": "helm-resources"},
+			creds: aws.Credentials{AccessKeyID: "ASIASTS", SecretAccessKey: "sts-secret", SessionToken: "sts-session"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenFile := filepath.Join(t.TempDir(), "token")
+			assert.NoError(t, os.WriteFile(tokenFile, []byte("web-identity-token"), 0o600))
+
+			sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, r.ParseForm())
+				assert.Equal(t, "AssumeRoleWithWebIdentity", r.PostForm.Get("Action"))
+				assert.Equal(t, "web-identity-token", r.PostForm.Get("WebIdentityToken"))
+
+				w.Header().Set("Content-Type", "text/xml")
+				fmt.Fprint(w, `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+<AssumeRoleWithWebIdentityResult><Credentials>
+<AccessKeyId>ASIASTS</AccessKeyId><SecretAccessKey>sts-secret</SecretAccessKey><SessionToken>sts-session</SessionToken>
+<Expiration>2099-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`)
+			}))
+			defer sts.Close()
+
+			setAWSEnv(t, tt.env)
+			t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile)
+			t.Setenv("AWS_ENDPOINT_URL_STS", sts.URL)
+
+			now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			metrics.SetSigV4Clock(t, now)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+
+				// The signature is recomputed from the received request with the fixed clock and credentials.
+				req, err := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), bytes.NewReader(body))
+				assert.NoError(t, err)
+				req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+				req.Header.Set("Idempotency-Key", r.Header.Get("Idempotency-Key"))
+
+				hash := sha256.Sum256(body)
+				assert.NoError(t, v4.NewSigner().SignHTTP(t.Context(), tt.creds, req, hex.EncodeToString(hash[:]), "aps", "us-west-2", now))
+
+				assert.Equal(t, req.Header.Get("Authorization"), r.Header.Get("Authorization"))
+				assert.Equal(t, "20260101T000000Z", r.Header.Get("X-Amz-Date"))
+				assert.Equal(t, tt.creds.SessionToken, r.Header.Get("X-Amz-Security-Token"))
+				assert.Equal(t, "5m", r.URL.Query().Get("max_source_resolution"))
+
+				fmt.Fprint(w, `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [1700000000, "100"]}]}}`)
+			}))
+			defer server.Close()
+
+			client, err := metrics.New(metrics.Options{
+				PrometheusURL: server.URL,
+				Prometheus: metrics.PrometheusOptions{
+					SigV4:               &metrics.SigV4Options{Region: "us-west-2"},
+					MaxSourceResolution: "5m",
+				},
+				Sources:       []string{metrics.SourcePrometheus},
+				MetricsWindow: "5m",
+				Aggregation:   "avg",
+			}, nil)
+			assert.NoError(t, err)
+
+			usage := client.GetContainerUsage(t.Context(), "prod", resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: "web"})
+			assert.Equal(t, int64(100), usage.CPU)
+		})
+	}
+}
+
+func TestParsePrometheusService(t *testing.T) {
+	for _, tt := range []struct {
+		ref       string
+		namespace string
+		service   string
+		path      string
+		err       bool
+	}{
+		{ref: "monitoring/prometheus-k8s:9090", namespace: "monitoring", service: "prometheus-k8s:9090"},
+		{ref: "monitoring/prometheus", namespace: "monitoring", service: "prometheus:9090"},
+		{ref: "monitoring/prometheus:web", namespace: "monitoring", service: "prometheus:web"},
+		{ref: "monitoring/https:thanos-query:10902", namespace: "monitoring", service: "https:thanos-query:10902"},
+		{ref: "vm/vmselect:8481/select/0/prometheus", namespace: "vm", service: "vmselect:8481", path: "/select/0/prometheus"},
+		{ref: "prometheus:9090", err: true},
+		{ref: "monitoring/", err: true},
+		{ref: "monitoring/:9090", err: true},
+	} {
+		t.Run(tt.ref, func(t *testing.T) {
+			namespace, service, path, err := metrics.ParsePrometheusService(tt.ref)
+			if tt.err {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.namespace, namespace)
+			assert.Equal(t, tt.service, service)
+			assert.Equal(t, tt.path, path)
+		})
+	}
+}
+
+func TestPrometheusService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer kube-token", r.Header.Get("Authorization"))
+
+		if r.URL.Path != "/api/v1/namespaces/monitoring/services/prometheus-k8s:9090/proxy/api/v1/query" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		fmt.Fprint(w, `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [1700000000, "100"]}]}}`)
+	}))
+	defer server.Close()
+
+	client, err := metrics.New(metrics.Options{
+		PrometheusService: "monitoring/prometheus-k8s:9090",
+		Sources:           []string{metrics.SourcePrometheus},
+		MetricsWindow:     "5m",
+		Aggregation:       "avg",
+	}, &rest.Config{Host: server.URL, BearerToken: "kube-token"})
+	assert.NoError(t, err)
+
+	usage := client.GetContainerUsage(t.Context(), "prod", resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: "web"})
+	assert.Equal(t, int64(100), usage.CPU)
+}
+
+func TestDiscoverPrometheus(t *testing.T) {
+	tests := []struct {
+		name       string
+		prometheus string
+		services   map[string]string
+		proxy      string
+		expected   *metrics.PrometheusEndpoint
+	}{
+		{
+			name:       "prometheus operator",
+			prometheus: `{"items": [{"metadata": {"name": "k8s", "namespace": "monitoring"}}]}`,
+			proxy:      "/api/v1/namespaces/monitoring/services/prometheus-operated:web/proxy/api/v1/query",
+			expected:   &metrics.PrometheusEndpoint{Kind: "Prometheus Operator", Service: "monitoring/prometheus-operated:web"},
+		},
+		{
+			name: "vmselect",
+			services: map[string]string{
+				"app.kubernetes.io/name=vmselect": `{"items": [{"metadata": {"name": "vmselect-vm", "namespace": "vm"},
+  "spec": {"ports": [{"name": "http", "port": 8481}]}}]}`,
+			},
+			proxy:    "/api/v1/namespaces/vm/services/vmselect-vm:http/proxy/select/0/prometheus/api/v1/query",
+			expected: &metrics.PrometheusEndpoint{Kind: "VictoriaMetrics vmselect", Service: "vm/vmselect-vm:http/select/0/prometheus"},
+		},
+		{
+			name:       "unreachable prometheus operator",
+			prometheus: `{"items": [{"metadata": {"name": "k8s", "namespace": "monitoring"}}]}`,
+			services: map[string]string{
+				"app.kubernetes.io/name=vmselect": `{"items": [{"metadata": {"name": "vmselect-vm", "namespace": "vm"},
+  "spec": {"ports": [{"name": "http", "port": 8481}]}}]}`,
+			},
+			proxy:    "/api/v1/namespaces/vm/services/vmselect-vm:http/proxy/select/0/prometheus/api/v1/query",
+			expected: &metrics.PrometheusEndpoint{Kind: "VictoriaMetrics vmselect", Service: "vm/vmselect-vm:http/select/0/prometheus"},
+		},
+		{
+			name: "unreachable",
+			services: map[string]string{
+				"app.kubernetes.io/name=prometheus": `{"items": [{"metadata": {"name": "prometheus", "namespace": "monitoring"},
+  "spec": {"ports": [{"port": 9090}]}}]}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+
+				switch {
+				case r.URL.Path == "/apis/monitoring.coreos.com/v1/prometheuses" && tt.prometheus != "":
+					fmt.Fprint(w, tt.prometheus)
+				case r.URL.Path == "/api/v1/services":
+					list, ok := tt.services[r.URL.Query().Get("labelSelector")]
+					if !ok {
+						list = `{"items": []}`
+					}
+
+					fmt.Fprint(w, list)
+				case r.URL.Path == tt.proxy:
+					assert.Equal(t, "1", r.URL.Query().Get("query"))
+
+					fmt.Fprint(w, `{"status": "success", "data": {"resultType": "scalar", "result": [1700000000, "1"]}}`)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			endpoint, err := metrics.DiscoverPrometheus(t.Context(), &rest.Config{Host: server.URL})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, endpoint)
+		})
+	}
+}
+
+func TestPrometheusQueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		workload string
+		queries  metrics.PrometheusQueries
+		expected []string
+	}{
+		{
+			name: "cadvisor",
+			expected: []string{
+				`avg(rate(container_cpu_usage_seconds_total{ namespace="prod",pod=~"web-[a-z0-9]{1,10}-[a-z0-9]{5}",container="web" }[5m])) * 1000`,
+				`sum by (pod) (avg_over_time((container_memory_working_set_bytes{ namespace="prod",pod=~"web-[a-z0-9]{1,10}-[a-z0-9]{5}",container="web" })[5m:1m]))`,
+			},
+		},
+		{
+			name:     "dotted workload name",
+			workload: "api.v2",
+			expected: []string{
+				`avg(rate(container_cpu_usage_seconds_total{ namespace="prod",pod=~"api\\.v2-[a-z0-9]{1,10}-[a-z0-9]{5}",container="web" }[5m])) * 1000`,
+			},
+		},
+		{
+			name: "relabeled with cluster matcher",
+			queries: metrics.PrometheusQueries{
+				Labels:   metrics.PrometheusLabels{Namespace: "k8s_namespace", Container: "container_name"},
+				Matchers: []string{`cluster="eu-west"`},
+			},
+			expected: []string{
+				`avg(rate(container_cpu_usage_seconds_total{ k8s_namespace="prod",pod=~"web-[a-z0-9]{1,10}-[a-z0-9]{5}",container_name="web",cluster="eu-west" }[5m])) * 1000`,
+			},
+		},
+		{
+			name: "grafana agent",
+			queries: metrics.PrometheusQueries{
+				Preset: metrics.PresetGrafanaAgent,
+			},
+			expected: []string{
+				`sum by (container_label_io_kubernetes_pod_name) (rate(container_cpu_usage_seconds_total{ container_label_io_kubernetes_pod_namespace="prod",` +
+					`container_label_io_kubernetes_pod_name=~"web-[a-z0-9]{1,10}-[a-z0-9]{5}",container_label_io_kubernetes_container_name="web" }[5m])) * 1000`,
+			},
+		},
+		{
+			name: "template",
+			queries: metrics.PrometheusQueries{
+				Templates: map[string]string{
+					metrics.SignalMemory: `container_memory_working_set_bytes{namespace="{{.Namespace}}",pod=~"{{.Workload}}-.*",container="{{.Container}}"}`,
+				},
+			},
+			expected: []string{
+				`avg(avg_over_time((container_memory_working_set_bytes{namespace="prod",pod=~"web-.*",container="web"})[5m:1m]))`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries []string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, r.ParseForm())
+				queries = append(queries, r.Form.Get("query"))
+
+				fmt.Fprint(w, `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [1700000000, "100"]}]}}`)
+			}))
+			defer server.Close()
+
+			client, err := metrics.New(metrics.Options{
+				PrometheusURL: server.URL,
+				Prometheus:    metrics.PrometheusOptions{Queries: tt.queries},
+				Sources:       []string{metrics.SourcePrometheus},
+				MetricsWindow: "5m",
+				Aggregation:   "avg",
+			}, nil)
+			assert.NoError(t, err)
+
+			client.GetContainerUsage(t.Context(), "prod", resources.ResourceInfo{Kind: "Deployment", Name: cmp.Or(tt.workload, "web"), Container: "web"})
+
+			for _, query := range tt.expected {
+				assert.Contains(t, queries, query)
+			}
+		})
+	}
+}
+
+func TestPrometheusQueriesValidate(t *testing.T) {
+	assert.NoError(t, metrics.PrometheusQueries{Preset: metrics.PresetGrafanaAgent}.Validate())
+	assert.Error(t, metrics.PrometheusQueries{Preset: "graphite"}.Validate())
+	assert.Error(t, metrics.PrometheusQueries{Templates: map[string]string{"network": "x"}}.Validate())
+	assert.Error(t, metrics.PrometheusQueries{Templates: map[string]string{metrics.SignalCPU: "rate({{.Matchers}"}}.Validate())
+	assert.Error(t, metrics.PrometheusQueries{Templates: map[string]string{metrics.SignalCPU: "rate({{.Cluster}})"}}.Validate())
+}
+
+func TestMetricsServerSampling(t *testing.T) {
+	// The first pod is sampled at 100m..600m, quantile_over_time of Prometheus returns the same values for this series.
+	rising := []int64{100, 200, 300, 400, 500, 600}
+	steady := []int64{1000, 1000, 1000, 1000, 1000, 1000}
+
+	tests := []struct {
+		name        string
+		kind        string
+		aggregation string
+		pods        map[string][]int64
+		statistics  []string
+		expectCPU   int64
+		expectStats map[string]int64
+	}{
+		{
+			name:        "statistics of a series",
+			kind:        "Deployment",
+			aggregation: "avg",
+			pods:        map[string][]int64{"web-7d9f8b6c5d-abcde": rising},
+			statistics:  []string{"p50", "p90", "p99", "avg", "max", "stddev"},
+			expectCPU:   350,
+			expectStats: map[string]int64{"p50": 350, "p90": 550, "p99": 595, "avg": 350, "max": 600, "stddev": 170},
+		},
+		{
+			name:        "stddev of a steady series",
+			kind:        "Deployment",
+			aggregation: "avg",
+			pods:        map[string][]int64{"web-7d9f8b6c5d-abcde": steady},
+			statistics:  []string{"p90", "stddev"},
+			expectCPU:   1000,
+			expectStats: map[string]int64{"p90": 1000, "stddev": 0},
+		},
+		{
+			name:        "avg aggregation of pods",
+			kind:        "Deployment",
+			aggregation: "avg",
+			pods:        map[string][]int64{"web-7d9f8b6c5d-abcde": rising, "web-7d9f8b6c5d-fghij": steady},
+			statistics:  []string{"p90", "max"},
+			expectCPU:   675,
+			expectStats: map[string]int64{"p90": 775, "max": 800},
+		},
+		{
+			name:        "max aggregation of pods",
+			kind:        "Deployment",
+			aggregation: "max",
+			pods:        map[string][]int64{"web-7d9f8b6c5d-abcde": rising, "web-7d9f8b6c5d-fghij": steady},
+			statistics:  []string{"p90", "max"},
+			expectCPU:   800,
+			expectStats: map[string]int64{"p90": 1000, "max": 1000},
+		},
+		{
+			name:        "statefulset sized for the busiest pod",
+			kind:        "StatefulSet",
+			aggregation: "avg",
+			pods:        map[string][]int64{"web-0": rising, "web-1": steady, "web-api-0": steady},
+			statistics:  []string{"p90"},
+			expectCPU:   675,
+			expectStats: map[string]int64{"p90": 1000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/apis/metrics.k8s.io/v1beta1/namespaces/prod/pods" {
+					http.NotFound(w, r)
+
+					return
+				}
+
+				var items []string
+
+				for pod, values := range tt.pods {
+					cpu := values[min(calls, len(values)-1)]
+					items = append(items, fmt.Sprintf(`{"metadata": {"name": %q, "namespace": "prod"},
+						"containers": [{"name": "web", "usage": {"cpu": "%dm", "memory": "%dMi"}}]}`, pod, cpu, cpu))
+				}
+
+				calls++
+
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"kind": "PodMetricsList", "apiVersion": "metrics.k8s.io/v1beta1", "items": [%s]}`, strings.Join(items, ","))
+			}))
+			defer server.Close()
+
+			client, err := metrics.New(metrics.Options{
+				Sources:        []string{metrics.SourceMetricsServer},
+				MetricsWindow:  "50ms",
+				SampleInterval: 10 * time.Millisecond,
+				Aggregation:    tt.aggregation,
+				Statistics:     tt.statistics,
+			}, &rest.Config{Host: server.URL})
+			assert.NoError(t, err)
+
+			assert.NoError(t, client.Sample(t.Context(), "prod", nil))
+			assert.Equal(t, 6, calls)
+
+			usage := client.GetContainerUsage(t.Context(), "prod", resources.ResourceInfo{Kind: tt.kind, Name: "web", Container: "web"})
+			assert.Equal(t, tt.expectCPU, usage.CPU)
+			assert.Equal(t, tt.expectStats, usage.CPUStats)
+		})
+	}
+}
+
+func TestSeriesStatistic(t *testing.T) {
+	tens := []int64{100, 90, 80, 70, 60, 50, 40, 30, 20, 10}
+
+	tests := []struct {
+		name   string
+		values []int64
+		stat   string
+		expect int64
+		ok     bool
+	}{
+		// The ranks of quantile_over_time are interpolated: p50 of 10 samples is at rank 4.5, p99.9 at rank 8.991.
+		{name: "p50", values: tens, stat: "p50", expect: 55, ok: true},
+		{name: "p99.9", values: tens, stat: "p99.9", expect: 99, ok: true},
+		{name: "p95 of a single sample", values: []int64{42}, stat: "p95", expect: 42, ok: true},
+		{name: "avg", values: tens, stat: "avg", expect: 55, ok: true},
+		{name: "max", values: tens, stat: "max", expect: 100, ok: true},
+		{name: "stddev", values: []int64{2, 4, 4, 4, 5, 5, 7, 9}, stat: "stddev", expect: 2, ok: true},
+		{name: "empty input", stat: "p50"},
+		{name: "unknown statistic", values: tens, stat: "median"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := metrics.SeriesStatistic(tt.values, tt.stat)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expect, value)
+		})
+	}
+}
+
+func TestMetricsServerSamplingLimit(t *testing.T) {
+	client, err := metrics.New(metrics.Options{
+		Sources:        []string{metrics.SourceMetricsServer},
+		MetricsWindow:  "7d",
+		SampleInterval: 15 * time.Second,
+	}, &rest.Config{Host: "http://127.0.0.1:1"})
+	assert.NoError(t, err)
+
+	err = client.Sample(t.Context(), "prod", nil)
+	assert.ErrorContains(t, err, "takes 40321 samples, more than 1000")
+}
+
+func TestParseCadvisorCPU(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		expect map[string]map[string]metrics.CPUCounter
+	}{
+		{
+			name: "counters with timestamps",
+			data: `# HELP container_cpu_usage_seconds_total Cumulative cpu time consumed in seconds.
+# TYPE container_cpu_usage_seconds_total counter
+container_cpu_usage_seconds_total{container="web",cpu="total",namespace="prod",pod="web-7d9f8b6c5d-abcde"} 12.5 1700000000000
+container_cpu_usage_seconds_total{container="",cpu="total",namespace="prod",pod="web-7d9f8b6c5d-abcde"} 13 1700000000000
+container_cpu_usage_seconds_total{container="POD",cpu="total",namespace="prod",pod="web-7d9f8b6c5d-abcde"} 0.1 1700000000000
+container_cpu_usage_seconds_total{container="web",cpu="total",namespace="other",pod="web-7d9f8b6c5d-fghij"} 7 1700000000000
+# HELP container_memory_working_set_bytes Current working set in bytes.
+# TYPE container_memory_working_set_bytes gauge
+container_memory_working_set_bytes{container="web",namespace="prod",pod="web-7d9f8b6c5d-abcde"} 1.048576e+08 1700000000000
+`,
+			expect: map[string]map[string]metrics.CPUCounter{
+				"web-7d9f8b6c5d-abcde": {"web": {Seconds: 12.5, Time: 1700000000000}},
+			},
+		},
+		{
+			name: "counters without timestamps",
+			data: `container_cpu_usage_seconds_total{container="db",namespace="prod",pod="db-0"} 3.25
+`,
+			expect: map[string]map[string]metrics.CPUCounter{
+				"db-0": {"db": {Seconds: 3.25, Time: 1700000005000}},
+			},
+		},
+		{
+			name: "no cpu counters",
+			data: `container_memory_working_set_bytes{container="web",namespace="prod",pod="web-0"} 1024
+`,
+		},
+		{
+			name: "invalid metrics",
+			data: `container_cpu_usage_seconds_total{container="web" 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, metrics.ParseCadvisorCPU(tt.data, "prod", 1700000005000))
+		})
+	}
+}
+
+func TestCPURate(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous metrics.CPUCounter
+		current  metrics.CPUCounter
+		expect   int64
+		ok       bool
+	}{
+		{
+			name:     "rate between scrapes",
+			previous: metrics.CPUCounter{Seconds: 100, Time: 1700000000000},
+			current:  metrics.CPUCounter{Seconds: 105, Time: 1700000020000},
+			expect:   250,
+			ok:       true,
+		},
+		{
+			name:     "equal timestamps",
+			previous: metrics.CPUCounter{Seconds: 100, Time: 1700000000000},
+			current:  metrics.CPUCounter{Seconds: 105, Time: 1700000000000},
+		},
+		{
+			name:     "counter not refreshed",
+			previous: metrics.CPUCounter{Seconds: 100, Time: 1700000000000},
+			current:  metrics.CPUCounter{Seconds: 100, Time: 1700000020000},
+		},
+		{
+			name:     "counter reset",
+			previous: metrics.CPUCounter{Seconds: 100, Time: 1700000000000},
+			current:  metrics.CPUCounter{Seconds: 2, Time: 1700000020000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpu, ok := metrics.CPURate(tt.previous, tt.current)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expect, cpu)
+		})
+	}
+}
+
+func TestParseStatsSummary(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		expect    map[string]map[string]int64
+		expectErr bool
+	}{
+		{
+			name: "working set of the namespace containers",
+			data: `{"node": {"nodeName": "node-1"}, "pods": [
+				{"podRef": {"name": "web-7d9f8b6c5d-abcde", "namespace": "prod"}, "containers": [
+					{"name": "web", "memory": {"workingSetBytes": 104857600, "usageBytes": 209715200}},
+					{"name": "sidecar", "memory": {"usageBytes": 1048576}},
+					{"name": "init"}
+				]},
+				{"podRef": {"name": "web-7d9f8b6c5d-fghij", "namespace": "other"}, "containers": [
+					{"name": "web", "memory": {"workingSetBytes": 1}}
+				]}
+			]}`,
+			expect: map[string]map[string]int64{"web-7d9f8b6c5d-abcde": {"web": 104857600}},
+		},
+		{
+			name:   "no pods",
+			data:   `{"node": {"nodeName": "node-1"}}`,
+			expect: map[string]map[string]int64{},
+		},
+		{
+			name:      "invalid summary",
+			data:      `{"pods": [`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory, err := metrics.ParseStatsSummary(tt.data, "prod")
+			if tt.expectErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, memory)
+		})
+	}
+}
+
+func TestVPAListedOnce(t *testing.T) {
+	lists := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/apis/autoscaling.k8s.io/v1/namespaces/prod/verticalpodautoscalers", r.URL.Path)
+
+		lists++
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"apiVersion": "autoscaling.k8s.io/v1", "kind": "VerticalPodAutoscalerList", "items": [{
+  "metadata": {"name": "web", "namespace": "prod"},
+  "spec": {"targetRef": {"apiVersion": "apps/v1", "kind": "Deployment", "name": "web"}},
+  "status": {"recommendation": {"containerRecommendations": [
+    {"containerName": "web", "target": {"cpu": "250m", "memory": "256Mi"}},
+    {"containerName": "proxy", "target": {"cpu": "50m", "memory": "64Mi"}}
+  ]}}
+}]}`)
+	}))
+	defer server.Close()
+
+	client, err := metrics.New(metrics.Options{Sources: []string{metrics.SourceVPA}}, &rest.Config{Host: server.URL})
+	assert.NoError(t, err)
+
+	for _, container := range []string{"web", "proxy", "web"} {
+		usage := client.GetContainerUsage(t.Context(), "prod", resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: container})
+		assert.Equal(t, metrics.SourceVPA, usage.Source)
+		assert.NotNil(t, usage.VPA)
+	}
+
+	assert.Equal(t, 1, lists)
+}
+
+// proxyClientset is a fake clientset which serves the node proxy requests from a test server.
+type proxyClientset struct {
+	*fake.Clientset
+	proxy rest.Interface
+}
+
+func (c proxyClientset) CoreV1() typedcorev1.CoreV1Interface {
+	return proxyCoreV1{CoreV1Interface: c.Clientset.CoreV1(), proxy: c.proxy}
+}
+
+type proxyCoreV1 struct {
+	typedcorev1.CoreV1Interface
+	proxy rest.Interface
+}
+
+func (c proxyCoreV1) RESTClient() rest.Interface {
+	return c.proxy
+}
+
+func TestKubeletUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/nodes/node-1/proxy/metrics/cadvisor":
+			// The counter has not moved between the scrapes.
+			fmt.Fprintln(w, `container_cpu_usage_seconds_total{container="web",namespace="prod",pod="web-7d9f8b6c5d-abcde"} 12.5 1700000000000`)
+		case "/api/v1/nodes/node-1/proxy/stats/summary":
+			fmt.Fprint(w, `{"pods": [
+				{"podRef": {"name": "web-7d9f8b6c5d-abcde", "namespace": "prod"}, "containers": [
+					{"name": "web", "memory": {"workingSetBytes": 104857600}}
+				]}
+			]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	proxy, err := rest.RESTClientFor(&rest.Config{
+		Host:    srv.URL,
+		APIPath: "/api",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion:         &corev1.SchemeGroupVersion,
+			NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		},
+	})
+	assert.NoError(t, err)
+
+	var clientset kubernetes.Interface = proxyClientset{
+		Clientset: fake.NewClientset(
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-7d9f8b6c5d-abcde", Namespace: "prod"},
+				Spec:       corev1.PodSpec{NodeName: "node-1"},
+			},
+		),
+		proxy: proxy,
+	}
+
+	provider := metrics.NewKubeletProvider(clientset, time.Millisecond)
+
+	usage := provider.GetContainerUsage(t.Context(), "prod", resources.ResourceInfo{
+		Kind:      "Deployment",
+		Name:      "web",
+		Container: "web",
+	})
+	assert.Equal(t, resources.ContainerUsage{
+		Pods: []resources.PodUsage{{Pod: "web-7d9f8b6c5d-abcde", Mem: 104857600}},
+		Mem:  104857600,
+	}, usage)
+}
+
+func TestSkipFailingSource(t *testing.T) {
+	var warnings strings.Builder
+
+	client, err := metrics.New(metrics.Options{
+		PrometheusService: "monitoring/prometheus-k8s",
+		Prometheus:        metrics.PrometheusOptions{BearerToken: "token"},
+		OpenCostURL:       "http://127.0.0.1:1",
+		Sources:           []string{metrics.SourcePrometheus, metrics.SourceOpenCost},
+		Warnings:          &warnings,
+	}, &rest.Config{Host: "http://127.0.0.1:1"})
+	assert.NoError(t, err)
+	assert.Equal(t, metrics.SourceOpenCost, client.Name())
+	assert.Contains(t, warnings.String(), "Warning: skipping the prometheus metrics source: prometheus: authentication and TLS options cannot be used with a service")
+
+	_, err = metrics.New(metrics.Options{
+		PrometheusService: "monitoring/prometheus-k8s",
+		Prometheus:        metrics.PrometheusOptions{BearerToken: "token"},
+		Sources:           []string{metrics.SourcePrometheus},
+	}, &rest.Config{Host: "http://127.0.0.1:1"})
+	assert.Error(t, err)
+}
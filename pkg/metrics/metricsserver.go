@@ -0,0 +1,116 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	metricsv1 "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// metricsServerProvider reads the usage from the Kubernetes Metrics API (metrics.k8s.io/v1),
+// either the current snapshot or the samples collected by Sample.
+type metricsServerProvider struct {
+	metricsClient  metricsv1.Interface
+	samples        podSamples
+	sampleInterval time.Duration
+	metricsWindow  string
+	aggregation    string
+	statistics     []string
+}
+
+func newMetricsServerProvider(opts Options, config *rest.Config) (Provider, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	metricsClient, err := metricsv1.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metricsServerProvider{
+		metricsClient:  metricsClient,
+		sampleInterval: opts.SampleInterval,
+		metricsWindow:  opts.MetricsWindow,
+		aggregation:    opts.Aggregation,
+		statistics:     opts.Statistics,
+	}, nil
+}
+
+// Name returns the name of the provider.
+func (m *metricsServerProvider) Name() string {
+	return SourceMetricsServer
+}
+
+// GetContainerUsage returns the usage of the container from the samples, or the current usage without samples.
+func (m *metricsServerProvider) GetContainerUsage(ctx context.Context, namespace string, res resources.ResourceInfo) resources.ContainerUsage {
+	if m.samples != nil {
+		return m.getSampledUsage(res)
+	}
+
+	var usage resources.ContainerUsage
+
+	usage.Pods = m.getKubernetesPods(ctx, namespace, res)
+	usage.CPU, usage.Mem = averageUsage(usage.Pods)
+
+	return usage
+}
+
+// getKubernetesPods retrieves CPU and Memory usage of the container in every pod of the workload
+// from the Kubernetes Metrics API (metrics.k8s.io/v1).
+func (m *metricsServerProvider) getKubernetesPods(ctx context.Context, namespace string, res resources.ResourceInfo) []resources.PodUsage {
+	podMetricsList, err := m.metricsClient.MetricsV1beta1().PodMetricses(namespace).List(ctx, resources.ListOptions(res.Labels))
+	if err != nil {
+		return nil
+	}
+
+	var pods []resources.PodUsage
+
+	for _, podMetrics := range podMetricsList.Items {
+		podName := podMetrics.Name
+
+		if !resources.PodBelongsToWorkload(podName, res.Kind, res.Name) {
+			continue
+		}
+
+		for _, containerMetrics := range podMetrics.Containers {
+			if containerMetrics.Name != res.Container {
+				continue
+			}
+
+			pod := resources.PodUsage{Pod: podName}
+
+			if cpu, ok := containerMetrics.Usage[v1.ResourceCPU]; ok {
+				pod.CPU = cpu.MilliValue()
+			}
+
+			if mem, ok := containerMetrics.Usage[v1.ResourceMemory]; ok {
+				pod.Mem = mem.Value()
+			}
+
+			pods = append(pods, pod)
+		}
+	}
+
+	return pods
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// openCostAggregate aggregates OpenCost allocations by container of the workloads.
+	openCostAggregate = "namespace,controllerKind,controller,container"
+	// openCostTimeout is the timeout of OpenCost allocation queries.
+	openCostTimeout = 60 * time.Second
+)
+
+// openCostResponse is the response of the OpenCost allocation API.
+type openCostResponse struct {
+	Code    int                             `json:"code"`
+	Message string                          `json:"message,omitempty"`
+	Data    []map[string]openCostAllocation `json:"data"`
+}
+
+// openCostAllocation is the part of an OpenCost allocation with the container usage and cost.
+type openCostAllocation struct {
+	Properties struct {
+		Namespace      string `json:"namespace"`
+		ControllerKind string `json:"controllerKind"`
+		Controller     string `json:"controller"`
+		Container      string `json:"container"`
+	} `json:"properties"`
+	CPUCoreUsageAverage float64  `json:"cpuCoreUsageAverage"`
+	CPUCoreUsageMax     *float64 `json:"cpuCoreUsageMax,omitempty"`
+	RAMByteUsageAverage float64  `json:"ramByteUsageAverage"`
+	RAMByteUsageMax     *float64 `json:"ramByteUsageMax,omitempty"`
+	CPUCost             float64  `json:"cpuCost"`
+	RAMCost             float64  `json:"ramCost"`
+	TotalCost           float64  `json:"totalCost"`
+}
+
+// openCostProvider reads the usage and cost of containers from the OpenCost allocation API.
+type openCostProvider struct {
+	url           string
+	client        *http.Client
+	metricsWindow string
+	warnings      io.Writer
+	// allocations of the namespace, queried once
+	allocations []openCostAllocation
+	namespace   string
+}
+
+func newOpenCostProvider(opts Options, _ *rest.Config) (Provider, error) {
+	if opts.OpenCostURL == "" {
+		return nil, nil
+	}
+
+	if _, err := url.Parse(opts.OpenCostURL); err != nil {
+		return nil, fmt.Errorf("invalid OpenCost URL: %w", err)
+	}
+
+	return &openCostProvider{
+		url:           strings.TrimSuffix(opts.OpenCostURL, "/"),
+		client:        &http.Client{Timeout: openCostTimeout},
+		metricsWindow: opts.MetricsWindow,
+		warnings:      opts.Warnings,
+	}, nil
+}
+
+// Name returns the name of the provider.
+func (m *openCostProvider) Name() string {
+	return SourceOpenCost
+}
+
+// GetContainerUsage returns the average and max usage, and the cost of the container over the metrics window.
+func (m *openCostProvider) GetContainerUsage(ctx context.Context, namespace string, res resources.ResourceInfo) resources.ContainerUsage {
+	for _, a := range m.getAllocations(ctx, namespace) {
+		p := a.Properties
+		if p.Controller != res.Name || p.Container != res.Container || !strings.EqualFold(p.ControllerKind, res.Kind) {
+			continue
+		}
+
+		usage := resources.ContainerUsage{
+			CPU:      int64(a.CPUCoreUsageAverage * 1000),
+			Mem:      int64(a.RAMByteUsageAverage),
+			CPUStats: map[string]int64{resources.StatisticAvg: int64(a.CPUCoreUsageAverage * 1000)},
+			MemStats: map[string]int64{resources.StatisticAvg: int64(a.RAMByteUsageAverage)},
+			Cost: &resources.Cost{
+				Window: m.metricsWindow,
+				CPU:    a.CPUCost,
+				Memory: a.RAMCost,
+				Total:  a.TotalCost,
+			},
+		}
+
+		if a.CPUCoreUsageMax != nil {
+			usage.CPUStats[resources.StatisticMax] = int64(*a.CPUCoreUsageMax * 1000)
+		}
+
+		if a.RAMByteUsageMax != nil {
+			usage.MemStats[resources.StatisticMax] = int64(*a.RAMByteUsageMax)
+		}
+
+		return usage
+	}
+
+	return resources.ContainerUsage{}
+}
+
+// getAllocations returns the container allocations of the namespace accumulated over the metrics window.
+// A failed query is reported to the warnings writer once, the namespace then has no allocations.
+func (m *openCostProvider) getAllocations(ctx context.Context, namespace string) []openCostAllocation {
+	if m.allocations != nil && m.namespace == namespace {
+		return m.allocations
+	}
+
+	allocations, err := m.queryAllocations(ctx, namespace)
+	if err != nil && m.warnings != nil {
+		fmt.Fprintf(m.warnings, "Warning: failed to query the OpenCost allocations of namespace %s: %v\n", namespace, err)
+	}
+
+	m.allocations, m.namespace = allocations, namespace
+
+	return m.allocations
+}
+
+// queryAllocations queries the OpenCost allocation API for the container allocations of the namespace.
+func (m *openCostProvider) queryAllocations(ctx context.Context, namespace string) ([]openCostAllocation, error) {
+	allocations := []openCostAllocation{}
+
+	query := url.Values{}
+	query.Set("window", m.metricsWindow)
+	query.Set("aggregate", openCostAggregate)
+	query.Set("accumulate", "true")
+	query.Set("filter", fmt.Sprintf(`namespace:"%s"`, namespace))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.url+"/allocation/compute?"+query.Encode(), nil)
+	if err != nil {
+		return allocations, err
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return allocations, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return allocations, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return allocations, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var result openCostResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return allocations, fmt.Errorf("failed to decode the response: %w", err)
+	}
+
+	for _, set := range result.Data {
+		for _, a := range set {
+			if a.Properties.Namespace == namespace {
+				allocations = append(allocations, a)
+			}
+		}
+	}
+
+	return allocations, nil
+}
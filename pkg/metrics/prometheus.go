@@ -0,0 +1,319 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	v1prometheus "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// statisticsRateInterval is the rate interval of the CPU usage series used for statistics.
+	statisticsRateInterval = "5m"
+	// statisticsResolution is the subquery resolution of statistics queries.
+	statisticsResolution = "1m"
+)
+
+// prometheusProvider reads the usage over the metrics window from Prometheus.
+type prometheusProvider struct {
+	prometheusClient v1prometheus.API
+	queries          *prometheusQueries
+	metricsWindow    string
+	aggregation      string
+	statistics       []string
+}
+
+func newPrometheusProvider(opts Options, config *rest.Config) (Provider, error) {
+	var (
+		address      = opts.PrometheusURL
+		roundTripper http.RoundTripper
+		err          error
+	)
+
+	switch {
+	case opts.PrometheusURL != "" && opts.PrometheusService != "":
+		return nil, fmt.Errorf("prometheus URL and service cannot be used together")
+	case opts.PrometheusURL != "":
+		roundTripper, err = prometheusRoundTripper(opts.Prometheus, config)
+	case opts.PrometheusService != "":
+		address, roundTripper, err = prometheusServiceProxy(opts.PrometheusService, opts.Prometheus, config)
+	default:
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	queries, err := newPrometheusQueries(opts.Prometheus.Queries)
+	if err != nil {
+		return nil, err
+	}
+
+	promClient, err := api.NewClient(api.Config{
+		Address:      address,
+		RoundTripper: roundTripper,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Prometheus client: %w", err)
+	}
+
+	return &prometheusProvider{
+		prometheusClient: v1prometheus.NewAPI(promClient),
+		queries:          queries,
+		metricsWindow:    opts.MetricsWindow,
+		aggregation:      opts.Aggregation,
+		statistics:       opts.Statistics,
+	}, nil
+}
+
+// Name returns the name of the provider.
+func (m *prometheusProvider) Name() string {
+	return SourcePrometheus
+}
+
+// GetContainerUsage returns the usage, per-pod usage, CPU throttling and usage statistics of the container.
+func (m *prometheusProvider) GetContainerUsage(ctx context.Context, namespace string, res resources.ResourceInfo) resources.ContainerUsage {
+	var usage resources.ContainerUsage
+
+	usage.CPU, usage.Mem = m.getPrometheusMetrics(ctx, namespace, res)
+	if usage.CPU == 0 && usage.Mem == 0 {
+		return usage
+	}
+
+	usage.Pods = m.getPrometheusPods(ctx, namespace, res)
+	usage.CPUThrottling = m.getPrometheusThrottling(ctx, namespace, res)
+	usage.CPUStats, usage.MemStats = m.getPrometheusStatistics(ctx, namespace, res)
+
+	return usage
+}
+
+// getPrometheusMetrics retrieves CPU and memory usage from Prometheus
+func (m *prometheusProvider) getPrometheusMetrics(ctx context.Context, namespace string, res resources.ResourceInfo) (int64, int64) {
+	cpu, err := m.queries.query(SignalCPU, namespace, res, m.metricsWindow, m.aggregation)
+	if err != nil {
+		return 0, 0
+	}
+
+	mem, err := m.queries.query(SignalMemory, namespace, res, m.metricsWindow, m.aggregation)
+	if err != nil {
+		return 0, 0
+	}
+
+	cpuResult, _, err := m.prometheusClient.Query(ctx, fmt.Sprintf(`%s(%s) * 1000`, m.aggregation, cpu), time.Now())
+	if err != nil {
+		return 0, 0
+	}
+
+	memResult, _, err := m.prometheusClient.Query(ctx, fmt.Sprintf(`%s(%s_over_time((%s)[%s:%s]))`,
+		m.aggregation, m.aggregation, mem, m.metricsWindow, statisticsResolution), time.Now())
+	if err != nil {
+		return 0, 0
+	}
+
+	var cpuUsage, memUsage int64
+
+	if cpuVector, ok := cpuResult.(model.Vector); ok && len(cpuVector) > 0 {
+		cpuUsage = int64(cpuVector[0].Value)
+	}
+
+	if memVector, ok := memResult.(model.Vector); ok && len(memVector) > 0 {
+		memUsage = int64(memVector[0].Value)
+	}
+
+	return cpuUsage, memUsage
+}
+
+// getPrometheusPods retrieves CPU and memory usage of the container in every pod of the workload from Prometheus.
+func (m *prometheusProvider) getPrometheusPods(ctx context.Context, namespace string, res resources.ResourceInfo) []resources.PodUsage {
+	podLabel := m.queries.labels.Pod
+
+	cpu, err := m.queries.query(SignalCPU, namespace, res, m.metricsWindow, m.aggregation)
+	if err != nil {
+		return nil
+	}
+
+	mem, err := m.queries.query(SignalMemory, namespace, res, m.metricsWindow, m.aggregation)
+	if err != nil {
+		return nil
+	}
+
+	cpuResult, _, err := m.prometheusClient.Query(ctx, fmt.Sprintf(`sum by (%s) (%s) * 1000`, podLabel, cpu), time.Now())
+	if err != nil {
+		return nil
+	}
+
+	memResult, _, err := m.prometheusClient.Query(ctx, fmt.Sprintf(`sum by (%s) (%s_over_time((%s)[%s:%s]))`,
+		podLabel, m.aggregation, mem, m.metricsWindow, statisticsResolution), time.Now())
+	if err != nil {
+		return nil
+	}
+
+	usage := map[string]*resources.PodUsage{}
+
+	pod := func(sample *model.Sample) *resources.PodUsage {
+		name := string(sample.Metric[model.LabelName(podLabel)])
+		if !resources.PodBelongsToWorkload(name, res.Kind, res.Name) {
+			return nil
+		}
+
+		if _, ok := usage[name]; !ok {
+			usage[name] = &resources.PodUsage{Pod: name}
+		}
+
+		return usage[name]
+	}
+
+	if cpuVector, ok := cpuResult.(model.Vector); ok {
+		for _, sample := range cpuVector {
+			if p := pod(sample); p != nil {
+				p.CPU = int64(sample.Value)
+			}
+		}
+	}
+
+	if memVector, ok := memResult.(model.Vector); ok {
+		for _, sample := range memVector {
+			if p := pod(sample); p != nil {
+				p.Mem = int64(sample.Value)
+			}
+		}
+	}
+
+	pods := make([]resources.PodUsage, 0, len(usage))
+	for _, p := range usage {
+		pods = append(pods, *p)
+	}
+
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Pod < pods[j].Pod })
+
+	return pods
+}
+
+// getPrometheusThrottling returns the ratio of throttled CFS periods of a container over the metrics window.
+func (m *prometheusProvider) getPrometheusThrottling(ctx context.Context, namespace string, res resources.ResourceInfo) float64 {
+	throttled, err := m.queries.query(SignalCPUThrottledPeriods, namespace, res, m.metricsWindow, m.aggregation)
+	if err != nil {
+		return 0
+	}
+
+	periods, err := m.queries.query(SignalCPUPeriods, namespace, res, m.metricsWindow, m.aggregation)
+	if err != nil {
+		return 0
+	}
+
+	ratio, ok := m.queryPrometheusValue(ctx, fmt.Sprintf(`sum(%s) / sum(%s)`, throttled, periods))
+	if !ok {
+		return 0
+	}
+
+	return ratio
+}
+
+// getPrometheusStatistics retrieves the configured CPU and memory usage statistics over the metrics window.
+// The statistic of every pod is aggregated across pods with the configured aggregation function,
+// or with max for the workloads sized for the busiest pod, see resources.SizedForBusiestPod.
+func (m *prometheusProvider) getPrometheusStatistics(ctx context.Context, namespace string, res resources.ResourceInfo) (map[string]int64, map[string]int64) {
+	if len(m.statistics) == 0 {
+		return nil, nil
+	}
+
+	aggregation := m.aggregation
+	if resources.SizedForBusiestPod(res.Kind) {
+		aggregation = "max"
+	}
+
+	cpu, err := m.queries.query(SignalCPU, namespace, res, statisticsRateInterval, aggregation)
+	if err != nil {
+		return nil, nil
+	}
+
+	mem, err := m.queries.query(SignalMemory, namespace, res, statisticsRateInterval, aggregation)
+	if err != nil {
+		return nil, nil
+	}
+
+	cpuSeries := fmt.Sprintf(`(%s)[%s:%s]`, cpu, m.metricsWindow, statisticsResolution)
+	memSeries := fmt.Sprintf(`(%s)[%s:%s]`, mem, m.metricsWindow, statisticsResolution)
+
+	cpuStats := map[string]int64{}
+	memStats := map[string]int64{}
+
+	for _, stat := range m.statistics {
+		cpuFunc, ok := overTimeFunction(stat, cpuSeries)
+		if !ok {
+			continue
+		}
+
+		if cpu, ok := m.queryPrometheusValue(ctx, fmt.Sprintf(`%s(%s) * 1000`, aggregation, cpuFunc)); ok {
+			cpuStats[stat] = int64(cpu)
+		}
+
+		memFunc, _ := overTimeFunction(stat, memSeries)
+		if mem, ok := m.queryPrometheusValue(ctx, fmt.Sprintf(`%s(%s)`, aggregation, memFunc)); ok {
+			memStats[stat] = int64(mem)
+		}
+	}
+
+	return cpuStats, memStats
+}
+
+// queryPrometheusValue runs an instant query and returns the value of the first sample.
+func (m *prometheusProvider) queryPrometheusValue(ctx context.Context, query string) (float64, bool) {
+	result, _, err := m.prometheusClient.Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, false
+	}
+
+	if vector, ok := result.(model.Vector); ok && len(vector) > 0 {
+		if value := float64(vector[0].Value); !math.IsNaN(value) && !math.IsInf(value, 0) {
+			return value, true
+		}
+	}
+
+	return 0, false
+}
+
+// overTimeFunction returns the PromQL over-time function computing the statistic of the range vector.
+func overTimeFunction(stat, series string) (string, bool) {
+	switch stat {
+	case resources.StatisticAvg:
+		return fmt.Sprintf("avg_over_time(%s)", series), true
+	case resources.StatisticMax:
+		return fmt.Sprintf("max_over_time(%s)", series), true
+	case resources.StatisticStdDev:
+		return fmt.Sprintf("stddev_over_time(%s)", series), true
+	}
+
+	if q, ok := resources.Quantile(stat); ok {
+		return fmt.Sprintf("quantile_over_time(%g, %s)", q, series), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// defaultPrometheusPort is the port of a Prometheus service reference without a port.
+const defaultPrometheusPort = "9090"
+
+// ParsePrometheusService parses a Prometheus service reference namespace/name[:port][/path], e.g. monitoring/prometheus-k8s:9090.
+// The port is a number or a port name, 9090 when it is not set. The name can have the https: scheme prefix
+// of the API server service proxy, e.g. monitoring/https:thanos-query:10902. The path is the prefix of
+// the Prometheus API, e.g. select/0/prometheus of VictoriaMetrics vmselect.
+func ParsePrometheusService(ref string) (namespace, service, path string, err error) {
+	parts := strings.SplitN(strings.TrimSpace(ref), "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("invalid Prometheus service %q, must be namespace/name[:port][/path]", ref)
+	}
+
+	namespace, service = parts[0], parts[1]
+	if len(parts) == 3 && strings.Trim(parts[2], "/") != "" {
+		path = "/" + strings.Trim(parts[2], "/")
+	}
+
+	name := strings.TrimPrefix(strings.TrimPrefix(service, "https:"), "http:")
+	if name == "" || strings.HasPrefix(name, ":") {
+		return "", "", "", fmt.Errorf("invalid Prometheus service %q, must be namespace/name[:port][/path]", ref)
+	}
+
+	if !strings.Contains(name, ":") {
+		service += ":" + defaultPrometheusPort
+	}
+
+	return namespace, service, path, nil
+}
+
+// prometheusServiceProxy returns the API server service proxy URL of the Prometheus service and its round tripper,
+// which authenticates with the Kubernetes configuration.
+func prometheusServiceProxy(ref string, opts PrometheusOptions, config *rest.Config) (string, http.RoundTripper, error) {
+	if config == nil {
+		return "", nil, fmt.Errorf("prometheus service %s requires a Kubernetes configuration", ref)
+	}
+
+	if opts.HasAuthOrTLS() {
+		return "", nil, fmt.Errorf("prometheus: authentication and TLS options cannot be used with a service, the API server proxy uses the kubeconfig credentials")
+	}
+
+	if err := opts.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	namespace, service, path, err := ParsePrometheusService(ref)
+	if err != nil {
+		return "", nil, err
+	}
+
+	host, _, err := rest.DefaultServerUrlFor(config)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid Kubernetes API server URL: %w", err)
+	}
+
+	rt, err := rest.TransportFor(config)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create Kubernetes transport: %w", err)
+	}
+
+	address := fmt.Sprintf("%s/api/v1/namespaces/%s/services/%s/proxy%s", strings.TrimSuffix(host.String(), "/"), namespace, service, path)
+
+	return address, withRequestOptions(opts, rt), nil
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+// Signals of the Prometheus queries. Every signal template renders an instant vector with a series per container.
+const (
+	// SignalCPU is the CPU usage in cores, a rate over the window.
+	SignalCPU = "cpu"
+	// SignalMemory is the memory usage in bytes.
+	SignalMemory = "memory"
+	// SignalCPUThrottledPeriods is the rate of throttled CFS periods over the window.
+	SignalCPUThrottledPeriods = "cpuThrottledPeriods"
+	// SignalCPUPeriods is the rate of CFS periods over the window.
+	SignalCPUPeriods = "cpuPeriods"
+)
+
+// Query presets of the metric and label naming conventions.
+const (
+	// PresetCAdvisor is the cAdvisor metrics of the kubelet, as scraped by the Prometheus Operator.
+	// The memory is the working set, the usage the kubelet evicts and the OOM killer acts on.
+	PresetCAdvisor = "cadvisor"
+	// PresetGrafanaAgent is the cAdvisor integration of Grafana Agent and Alloy, with the container_label_io_kubernetes labels.
+	PresetGrafanaAgent = "grafana-agent"
+)
+
+// PrometheusQueries configures the PromQL of the Prometheus source.
+// The templates are Go templates of the QueryData variables, e.g. rate(container_cpu_usage_seconds_total{ {{.Matchers}} }[{{.Window}}]).
+type PrometheusQueries struct {
+	// Preset is the base of the labels and templates, cadvisor when empty.
+	Preset string `json:"preset,omitempty"`
+	// Labels override the label names of the preset.
+	Labels PrometheusLabels `json:"labels,omitempty"`
+	// Matchers are added to the label matchers of every query, e.g. cluster="production".
+	Matchers []string `json:"matchers,omitempty"`
+	// Templates override the query templates of the preset, keyed by signal.
+	Templates map[string]string `json:"templates,omitempty"`
+}
+
+// PrometheusLabels are the label names of the namespace, pod and container of the series.
+type PrometheusLabels struct {
+	Namespace string `json:"namespace,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Container string `json:"container,omitempty"`
+}
+
+// QueryData are the variables of the query templates.
+type QueryData struct {
+	// Namespace of the workload.
+	Namespace string
+	// Workload is the workload name.
+	Workload string
+	// Pod is the regular expression of the workload pod names, not escaped for PromQL strings.
+	Pod string
+	// Container is the container name.
+	Container string
+	// Window is the range of rate functions.
+	Window string
+	// Aggregation is the aggregation function across pods, avg or max.
+	Aggregation string
+	// Matchers are the label matchers of the namespace, pods and container, and the extra matchers.
+	Matchers string
+}
+
+type queryPreset struct {
+	labels    PrometheusLabels
+	templates map[string]string
+}
+
+var queryPresets = map[string]queryPreset{
+	PresetCAdvisor: {
+		labels: PrometheusLabels{Namespace: "namespace", Pod: "pod", Container: "container"},
+		templates: map[string]string{
+			SignalCPU:                 `rate(container_cpu_usage_seconds_total{ {{.Matchers}} }[{{.Window}}])`,
+			SignalMemory:              `container_memory_working_set_bytes{ {{.Matchers}} }`,
+			SignalCPUThrottledPeriods: `rate(container_cpu_cfs_throttled_periods_total{ {{.Matchers}} }[{{.Window}}])`,
+			SignalCPUPeriods:          `rate(container_cpu_cfs_periods_total{ {{.Matchers}} }[{{.Window}}])`,
+		},
+	},
+	PresetGrafanaAgent: {
+		labels: PrometheusLabels{
+			Namespace: "container_label_io_kubernetes_pod_namespace",
+			Pod:       "container_label_io_kubernetes_pod_name",
+			Container: "container_label_io_kubernetes_container_name",
+		},
+		templates: map[string]string{
+			SignalCPU:                 `rate(container_cpu_usage_seconds_total{ {{.Matchers}} }[{{.Window}}])`,
+			SignalMemory:              `container_memory_working_set_bytes{ {{.Matchers}} }`,
+			SignalCPUThrottledPeriods: `rate(container_cpu_cfs_throttled_periods_total{ {{.Matchers}} }[{{.Window}}])`,
+			SignalCPUPeriods:          `rate(container_cpu_cfs_periods_total{ {{.Matchers}} }[{{.Window}}])`,
+		},
+	},
+}
+
+// PresetNames returns the names of the query presets.
+func PresetNames() []string {
+	return slices.Sorted(maps.Keys(queryPresets))
+}
+
+// Validate checks the preset, the signals and the syntax of the templates.
+func (q PrometheusQueries) Validate() error {
+	_, err := newPrometheusQueries(q)
+
+	return err
+}
+
+// prometheusQueries renders the queries of the signals.
+type prometheusQueries struct {
+	labels    PrometheusLabels
+	matchers  []string
+	templates map[string]*template.Template
+}
+
+func newPrometheusQueries(q PrometheusQueries) (*prometheusQueries, error) {
+	name := q.Preset
+	if name == "" {
+		name = PresetCAdvisor
+	}
+
+	preset, ok := queryPresets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown query preset %q, available presets: %s", q.Preset, strings.Join(PresetNames(), ", "))
+	}
+
+	queries := &prometheusQueries{labels: preset.labels, templates: map[string]*template.Template{}}
+
+	for _, label := range []struct {
+		value string
+		dst   *string
+	}{
+		{q.Labels.Namespace, &queries.labels.Namespace},
+		{q.Labels.Pod, &queries.labels.Pod},
+		{q.Labels.Container, &queries.labels.Container},
+	} {
+		if label.value != "" {
+			*label.dst = label.value
+		}
+	}
+
+	for _, matcher := range q.Matchers {
+		if matcher = strings.TrimSpace(matcher); matcher != "" {
+			queries.matchers = append(queries.matchers, matcher)
+		}
+	}
+
+	for signal := range q.Templates {
+		if _, ok := preset.templates[signal]; !ok {
+			return nil, fmt.Errorf("unknown query signal %q, available signals: %s", signal, strings.Join(slices.Sorted(maps.Keys(preset.templates)), ", "))
+		}
+	}
+
+	for signal, text := range preset.templates {
+		if custom := q.Templates[signal]; custom != "" {
+			text = custom
+		}
+
+		tmpl, err := template.New(signal).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s query template: %w", signal, err)
+		}
+
+		// Unknown variables are only reported when the template is executed.
+		if err := tmpl.Execute(io.Discard, QueryData{}); err != nil {
+			return nil, fmt.Errorf("invalid %s query template: %w", signal, err)
+		}
+
+		queries.templates[signal] = tmpl
+	}
+
+	return queries, nil
+}
+
+// query renders the template of the signal for the container of the workload.
+func (q *prometheusQueries) query(signal, namespace string, res resources.ResourceInfo, window, aggregation string) (string, error) {
+	pod := resources.PodNamePattern(res.Kind, res.Name)
+
+	matchers := append([]string{
+		fmt.Sprintf(`%s="%s"`, q.labels.Namespace, namespace),
+		// The backslashes of the escaped regexp metacharacters, e.g. \., are escaped in the PromQL string.
+		fmt.Sprintf(`%s=~"%s"`, q.labels.Pod, strings.ReplaceAll(pod, `\`, `\\`)),
+		fmt.Sprintf(`%s="%s"`, q.labels.Container, res.Container),
+	}, q.matchers...)
+
+	var query strings.Builder
+
+	err := q.templates[signal].Execute(&query, QueryData{
+		Namespace:   namespace,
+		Workload:    res.Name,
+		Pod:         pod,
+		Container:   res.Container,
+		Window:      window,
+		Aggregation: aggregation,
+		Matchers:    strings.Join(matchers, ","),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render %s query: %w", signal, err)
+	}
+
+	return query.String(), nil
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxSamples is the highest number of metrics-server samples over the metrics window,
+// Sample polls for the whole window so a larger count means a run of hours or days.
+const maxSamples = 1000
+
+// podSamples are the metrics-server samples of the containers, keyed by pod and container name.
+type podSamples map[string]map[string][]sample
+
+type sample struct {
+	cpu int64 // millicores
+	mem int64 // bytes
+}
+
+// Sample polls the Kubernetes Metrics API for the usage of all pods in the namespace every sample interval
+// over the metrics window. The samples replace the single metrics-server snapshot in GetContainerUsage,
+// and the usage statistics are computed from them the same way Prometheus does.
+// The progress is written to the progress writer, nil disables it.
+func (m *metricsServerProvider) Sample(ctx context.Context, namespace string, progress io.Writer) error {
+	if m.sampleInterval <= 0 {
+		return fmt.Errorf("sample interval must be positive")
+	}
+
+	window, err := model.ParseDuration(m.metricsWindow)
+	if err != nil {
+		return fmt.Errorf("invalid metrics window %q: %w", m.metricsWindow, err)
+	}
+
+	total := int(time.Duration(window)/m.sampleInterval) + 1
+	if total > maxSamples {
+		return fmt.Errorf("sampling the %s metrics window every %s takes %d samples, more than %d: use a longer interval or a shorter window",
+			m.metricsWindow, m.sampleInterval, total, maxSamples)
+	}
+
+	if progress == nil {
+		progress = io.Discard
+	}
+
+	fmt.Fprintf(progress, "Sampling metrics-server usage for %s\n", time.Duration(window))
+
+	samples := podSamples{}
+
+	ticker := time.NewTicker(m.sampleInterval)
+	defer ticker.Stop()
+
+	for tick := 1; ; tick++ {
+		podMetricsList, err := m.metricsClient.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+		if err == nil {
+			for _, podMetrics := range podMetricsList.Items {
+				if samples[podMetrics.Name] == nil {
+					samples[podMetrics.Name] = map[string][]sample{}
+				}
+
+				for _, c := range podMetrics.Containers {
+					samples[podMetrics.Name][c.Name] = append(samples[podMetrics.Name][c.Name], sample{
+						cpu: c.Usage.Cpu().MilliValue(),
+						mem: c.Usage.Memory().Value(),
+					})
+				}
+			}
+		}
+
+		fmt.Fprintf(progress, "\rSampling metrics-server usage: %d/%d samples", tick, total)
+
+		if tick >= total {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(progress)
+
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	fmt.Fprintln(progress)
+
+	if len(samples) == 0 {
+		return fmt.Errorf("no samples collected from the Kubernetes Metrics API")
+	}
+
+	m.samples = samples
+
+	return nil
+}
+
+// getSampledUsage returns the usage of the container from the metrics-server samples.
+func (m *metricsServerProvider) getSampledUsage(res resources.ResourceInfo) resources.ContainerUsage {
+	var (
+		usage  resources.ContainerUsage
+		series [][]sample
+	)
+
+	for _, podName := range slices.Sorted(maps.Keys(m.samples)) {
+		if !resources.PodBelongsToWorkload(podName, res.Kind, res.Name) {
+			continue
+		}
+
+		values := m.samples[podName][res.Container]
+		if len(values) == 0 {
+			continue
+		}
+
+		cpu, _ := seriesStatistic(values, m.aggregation, sampleCPU)
+		mem, _ := seriesStatistic(values, m.aggregation, sampleMem)
+
+		usage.Pods = append(usage.Pods, resources.PodUsage{Pod: podName, CPU: cpu, Mem: mem})
+		series = append(series, values)
+	}
+
+	usage.CPU, usage.Mem = averageUsage(usage.Pods)
+
+	if len(m.statistics) == 0 || len(series) == 0 {
+		return usage
+	}
+
+	aggregation := m.aggregation
+	if resources.SizedForBusiestPod(res.Kind) {
+		aggregation = resources.StatisticMax
+	}
+
+	usage.CPUStats = map[string]int64{}
+	usage.MemStats = map[string]int64{}
+
+	for _, stat := range m.statistics {
+		var cpuValues, memValues []int64
+
+		for _, values := range series {
+			if cpu, ok := seriesStatistic(values, stat, sampleCPU); ok {
+				cpuValues = append(cpuValues, cpu)
+			}
+
+			if mem, ok := seriesStatistic(values, stat, sampleMem); ok {
+				memValues = append(memValues, mem)
+			}
+		}
+
+		if len(cpuValues) > 0 {
+			usage.CPUStats[stat] = aggregate(cpuValues, aggregation)
+		}
+
+		if len(memValues) > 0 {
+			usage.MemStats[stat] = aggregate(memValues, aggregation)
+		}
+	}
+
+	return usage
+}
+
+func sampleCPU(s sample) int64 { return s.cpu }
+
+func sampleMem(s sample) int64 { return s.mem }
+
+// seriesStatistic computes the statistic of the samples, like the PromQL over-time functions.
+func seriesStatistic(samples []sample, stat string, value func(sample) int64) (int64, bool) {
+	values := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		values = append(values, float64(value(s)))
+	}
+
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	switch stat {
+	case resources.StatisticAvg:
+		return int64(mean(values)), true
+	case resources.StatisticMax:
+		return int64(slices.Max(values)), true
+	case resources.StatisticStdDev:
+		avg := mean(values)
+
+		var variance float64
+		for _, v := range values {
+			variance += (v - avg) * (v - avg)
+		}
+
+		return int64(math.Sqrt(variance / float64(len(values)))), true
+	}
+
+	q, ok := resources.Quantile(stat)
+	if !ok {
+		return 0, false
+	}
+
+	sort.Float64s(values)
+
+	// Linear interpolation between the closest ranks, as quantile_over_time does.
+	rank := q * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return int64(values[lower] + (values[upper]-values[lower])*(rank-float64(lower))), true
+}
+
+// aggregate aggregates the values of the pods with the aggregation function, avg or max.
+func aggregate(values []int64, aggregation string) int64 {
+	if aggregation == resources.StatisticMax {
+		return slices.Max(values)
+	}
+
+	var total int64
+	for _, v := range values {
+		total += v
+	}
+
+	return total / int64(len(values))
+}
+
+func mean(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+
+	return total / float64(len(values))
+}
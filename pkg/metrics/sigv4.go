@@ -0,0 +1,133 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// sigV4DefaultService is the signing name of Amazon Managed Service for Prometheus.
+const sigV4DefaultService = "aps"
+
+// sigV4Now is the clock of the signatures.
+var sigV4Now = time.Now
+
+// SigV4Options configures the AWS Signature Version 4 signing of Prometheus requests,
+// required by Amazon Managed Service for Prometheus.
+// Credentials are resolved by the default credential chain of the AWS SDK: the environment,
+// the shared credentials and config files of the profile, web identity (e.g. EKS IAM roles for service accounts),
+// SSO, and the container and instance metadata.
+type SigV4Options struct {
+	// Region of the workspace, AWS_REGION, AWS_DEFAULT_REGION or the profile region when empty.
+	Region string `json:"region,omitempty"`
+	// Profile of the shared credentials and config files, AWS_PROFILE or default when empty.
+	Profile string `json:"profile,omitempty"`
+	// Service is the signing name, aps when empty.
+	Service string `json:"service,omitempty"`
+}
+
+// sigV4RoundTripper signs every request with the credentials of the AWS credential chain.
+type sigV4RoundTripper struct {
+	region  string
+	service string
+	creds   aws.CredentialsProvider
+	signer  *v4.Signer
+	now     func() time.Time
+	rt      http.RoundTripper
+}
+
+func newSigV4RoundTripper(opts SigV4Options, rt http.RoundTripper) (http.RoundTripper, error) {
+	var loadOpts []func(*config.LoadOptions) error
+
+	if opts.Region != "" {
+		loadOpts = append(loadOpts, config.WithRegion(opts.Region))
+	}
+
+	if opts.Profile != "" {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(opts.Profile))
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("sigv4: failed to load the AWS configuration: %w", err)
+	}
+
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("sigv4: region is required, set it in the options or AWS_REGION")
+	}
+
+	if cfg.Credentials == nil {
+		return nil, fmt.Errorf("sigv4: no AWS credentials found")
+	}
+
+	service := opts.Service
+	if service == "" {
+		service = sigV4DefaultService
+	}
+
+	return &sigV4RoundTripper{
+		region:  cfg.Region,
+		service: service,
+		creds:   cfg.Credentials,
+		signer:  v4.NewSigner(),
+		now:     sigV4Now,
+		rt:      rt,
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *sigV4RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	creds, err := r.creds.Retrieve(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("sigv4: failed to retrieve AWS credentials: %w", err)
+	}
+
+	var body []byte
+
+	req = req.Clone(req.Context())
+
+	if req.Body != nil {
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close() //nolint:errcheck
+
+		if err != nil {
+			return nil, err
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+
+	payloadHash := sha256.Sum256(body)
+
+	if err := r.signer.SignHTTP(req.Context(), creds, req, hex.EncodeToString(payloadHash[:]), r.service, r.region, r.now()); err != nil {
+		return nil, fmt.Errorf("sigv4: failed to sign the request: %w", err)
+	}
+
+	return r.rt.RoundTrip(req)
+}
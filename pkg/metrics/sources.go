@@ -0,0 +1,100 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// SourceAuto tries the metrics file, Prometheus, OpenCost, metrics-server and VPA in this order.
+	SourceAuto = "auto"
+	// SourceFile reads the usage exported from another monitoring system from a CSV or JSON file.
+	SourceFile = "file"
+	// SourcePrometheus reads usage over the metrics window from Prometheus.
+	SourcePrometheus = "prometheus"
+	// SourceOpenCost reads the average usage and the cost over the metrics window from the OpenCost allocation API.
+	SourceOpenCost = "opencost"
+	// SourceMetricsServer reads the current usage from the Kubernetes Metrics API.
+	SourceMetricsServer = "metrics-server"
+	// SourceKubelet reads the current usage from the kubelet stats summary and cAdvisor metrics through the API server.
+	SourceKubelet = "kubelet"
+	// SourceVPA takes the usage from the target of the VerticalPodAutoscaler recommendation.
+	SourceVPA = "vpa"
+)
+
+// Factory creates the provider of a metrics source.
+// A nil provider without an error means the source is not configured, e.g. Prometheus without a URL.
+type Factory func(opts Options, config *rest.Config) (Provider, error)
+
+var registry = map[string]Factory{
+	SourceFile:          newFileProvider,
+	SourcePrometheus:    newPrometheusProvider,
+	SourceOpenCost:      newOpenCostProvider,
+	SourceMetricsServer: newMetricsServerProvider,
+	SourceKubelet:       newKubeletProvider,
+	SourceVPA:           newVPAProvider,
+}
+
+// Register adds a metrics source, it replaces the source of the same name.
+// Sources are registered before New is called, usually from an init function.
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// SourceNames returns the names of the metrics sources, the built-in ones first.
+func SourceNames() []string {
+	names := []string{SourceAuto, SourceFile, SourcePrometheus, SourceOpenCost, SourceMetricsServer, SourceKubelet, SourceVPA}
+
+	for _, name := range slices.Sorted(maps.Keys(registry)) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// ParseSources parses a comma-separated, ordered list of metrics sources.
+// The first source returning usage of a container is used, "auto" expands to the default order.
+func ParseSources(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" || strings.TrimSpace(value) == SourceAuto {
+		return []string{SourceFile, SourcePrometheus, SourceOpenCost, SourceMetricsServer, SourceVPA}, nil
+	}
+
+	var sources []string
+
+	for source := range strings.SplitSeq(value, ",") {
+		source = strings.TrimSpace(source)
+
+		if source == SourceAuto || !slices.Contains(SourceNames(), source) {
+			return nil, fmt.Errorf("unknown metrics source %q, available sources: %s, or a comma-separated list in fallback order",
+				source, strings.Join(SourceNames(), ", "))
+		}
+
+		if !slices.Contains(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+// PrometheusOptions configures the authentication, TLS and headers of the Prometheus connection, and the queries.
+type PrometheusOptions struct {
+	// BearerToken is sent in the Authorization header, BearerTokenFile is re-read when it changes.
+	BearerToken     string `json:"bearerToken,omitempty"`
+	BearerTokenFile string `json:"bearerTokenFile,omitempty"`
+	// Username and Password are the basic authentication credentials.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// SigV4 signs the requests with AWS Signature Version 4, e.g. for Amazon Managed Service for Prometheus.
+	SigV4 *SigV4Options `json:"sigv4,omitempty"`
+	// KubeconfigAuth authenticates with the kubeconfig credentials, including exec credential plugins.
+	KubeconfigAuth bool `json:"kubeconfigAuth,omitempty"`
+	// CAFile is the CA bundle of the server certificate, CertFile and KeyFile are the client certificate for mTLS.
+	CAFile             string `json:"caFile,omitempty"`
+	CertFile           string `json:"certFile,omitempty"`
+	KeyFile            string `json:"keyFile,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	// Headers are added to every request, e.g. X-Scope-OrgID for multi-tenant Mimir, Cortex or Loki.
+	Headers map[string]string `json:"headers,omitempty"`
+	// MaxSourceResolution is the Thanos max_source_resolution parameter: raw, 5m, 1h or auto.
+	MaxSourceResolution string `json:"maxSourceResolution,omitempty"`
+	// PartialResponse is the Thanos partial_response parameter.
+	PartialResponse *bool `json:"partialResponse,omitempty"`
+	// Queries configures the metric and label names of the queries.
+	Queries PrometheusQueries `json:"queries,omitempty"`
+}
+
+// HasAuthOrTLS reports whether authentication or TLS options are set. They apply to a Prometheus URL only,
+// the API server service proxy authenticates with the kubeconfig credentials.
+func (o PrometheusOptions) HasAuthOrTLS() bool {
+	return o.BearerToken != "" || o.BearerTokenFile != "" || o.Username != "" || o.Password != "" ||
+		o.SigV4 != nil || o.KubeconfigAuth || o.CAFile != "" || o.CertFile != "" || o.KeyFile != "" || o.InsecureSkipVerify
+}
+
+// Validate checks the options for conflicting settings.
+func (o PrometheusOptions) Validate() error {
+	tokenAuth := o.BearerToken != "" || o.BearerTokenFile != ""
+	basicAuth := o.Username != "" || o.Password != ""
+
+	if tokenAuth && basicAuth {
+		return fmt.Errorf("prometheus: bearer token and basic authentication cannot be used together")
+	}
+
+	if o.KubeconfigAuth && (tokenAuth || basicAuth) {
+		return fmt.Errorf("prometheus: kubeconfig authentication cannot be used with a bearer token or basic authentication")
+	}
+
+	if o.SigV4 != nil && (tokenAuth || basicAuth || o.KubeconfigAuth) {
+		return fmt.Errorf("prometheus: SigV4 signing cannot be used with other authentication")
+	}
+
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return fmt.Errorf("prometheus: client certificate and key must be set together")
+	}
+
+	switch o.MaxSourceResolution {
+	case "", "raw", "5m", "1h", "auto":
+	default:
+		return fmt.Errorf("prometheus: invalid maxSourceResolution %q, must be raw, 5m, 1h or auto", o.MaxSourceResolution)
+	}
+
+	if err := o.Queries.Validate(); err != nil {
+		return fmt.Errorf("prometheus: %w", err)
+	}
+
+	return nil
+}
+
+// prometheusRoundTripper returns the round tripper of the Prometheus client with the TLS, authentication,
+// headers, Thanos query parameters and SigV4 signing of the options.
+// The Kubernetes configuration is only used for the kubeconfig authentication.
+func prometheusRoundTripper(opts PrometheusOptions, config *rest.Config) (http.RoundTripper, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.InsecureSkipVerify, //nolint:gosec
+	}
+
+	if opts.CAFile != "" {
+		ca, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Prometheus CA file: %w", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in Prometheus CA file %s", opts.CAFile)
+		}
+	}
+
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Prometheus client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	var rt http.RoundTripper = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+		IdleConnTimeout: 30 * time.Second,
+	}
+
+	var err error
+
+	switch {
+	case opts.KubeconfigAuth:
+		if config == nil {
+			return nil, fmt.Errorf("prometheus: kubeconfig authentication requires a Kubernetes configuration")
+		}
+
+		rt, err = rest.HTTPWrappersForConfig(config, rt)
+	case opts.BearerToken != "" || opts.BearerTokenFile != "":
+		rt, err = transport.NewBearerAuthWithRefreshRoundTripper(opts.BearerToken, opts.BearerTokenFile, rt)
+	case opts.Username != "" || opts.Password != "":
+		rt = transport.NewBasicAuthRoundTripper(opts.Username, opts.Password, rt)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure Prometheus authentication: %w", err)
+	}
+
+	// The signature covers the query parameters, so the requests are signed after they are added.
+	if opts.SigV4 != nil {
+		if rt, err = newSigV4RoundTripper(*opts.SigV4, rt); err != nil {
+			return nil, fmt.Errorf("failed to configure Prometheus SigV4 signing: %w", err)
+		}
+	}
+
+	return withRequestOptions(opts, rt), nil
+}
+
+// withRequestOptions wraps the round tripper to add the headers and Thanos query parameters of the options.
+func withRequestOptions(opts PrometheusOptions, rt http.RoundTripper) http.RoundTripper {
+	params := map[string]string{}
+	if opts.MaxSourceResolution != "" {
+		params["max_source_resolution"] = opts.MaxSourceResolution
+	}
+
+	if opts.PartialResponse != nil {
+		params["partial_response"] = strconv.FormatBool(*opts.PartialResponse)
+	}
+
+	if len(opts.Headers) > 0 || len(params) > 0 {
+		rt = &requestRoundTripper{headers: opts.Headers, params: params, rt: rt}
+	}
+
+	return rt
+}
+
+// requestRoundTripper adds headers and query parameters to every request.
+type requestRoundTripper struct {
+	headers map[string]string
+	params  map[string]string
+	rt      http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *requestRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	for k, v := range r.headers {
+		req.Header.Set(k, v)
+	}
+
+	if len(r.params) > 0 {
+		query := req.URL.Query()
+		for k, v := range r.params {
+			query.Set(k, v)
+		}
+
+		req.URL.RawQuery = query.Encode()
+	}
+
+	return r.rt.RoundTrip(req)
+}
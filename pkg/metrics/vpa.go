@@ -0,0 +1,140 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	vpav1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	vpa "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
+	"k8s.io/client-go/rest"
+)
+
+// vpaProvider takes the usage from the target of the VerticalPodAutoscaler recommendation.
+type vpaProvider struct {
+	vpaClient vpa.Interface
+	// VPAs of the namespace, listed once
+	vpas      []vpav1.VerticalPodAutoscaler
+	namespace string
+}
+
+func newVPAProvider(_ Options, config *rest.Config) (Provider, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	provider, err := newVPA(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+func newVPA(config *rest.Config) (*vpaProvider, error) {
+	vpaClient, err := vpa.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vpaProvider{vpaClient: vpaClient}, nil
+}
+
+// Name returns the name of the provider.
+func (m *vpaProvider) Name() string {
+	return SourceVPA
+}
+
+// GetContainerUsage returns the target of the VPA recommendation as the usage of the container.
+func (m *vpaProvider) GetContainerUsage(ctx context.Context, namespace string, res resources.ResourceInfo) resources.ContainerUsage {
+	recommendation := m.getVPARecommendation(ctx, namespace, res)
+	if recommendation == nil {
+		return resources.ContainerUsage{}
+	}
+
+	return resources.ContainerUsage{
+		CPU: recommendation.Target.CPU,
+		Mem: recommendation.Target.Mem,
+		VPA: recommendation,
+	}
+}
+
+// getVPARecommendation retrieves the recommendation of the VerticalPodAutoscaler targeting the workload.
+// VPAs are matched by their target reference, so recommendation-only VPAs created by Goldilocks are found as well.
+func (m *vpaProvider) getVPARecommendation(ctx context.Context, namespace string, res resources.ResourceInfo) *resources.VPARecommendation {
+	for _, vpaItem := range m.listVPAs(ctx, namespace) {
+		if vpaItem.Spec.TargetRef == nil || vpaItem.Spec.TargetRef.Name != res.Name || vpaItem.Spec.TargetRef.Kind != res.Kind {
+			continue
+		}
+
+		if vpaItem.Status.Recommendation == nil {
+			continue
+		}
+
+		for _, containerMetrics := range vpaItem.Status.Recommendation.ContainerRecommendations {
+			if containerMetrics.ContainerName != res.Container {
+				continue
+			}
+
+			return &resources.VPARecommendation{
+				Name:           vpaItem.Name,
+				LowerBound:     resourceValues(containerMetrics.LowerBound),
+				Target:         resourceValues(containerMetrics.Target),
+				UpperBound:     resourceValues(containerMetrics.UpperBound),
+				UncappedTarget: resourceValues(containerMetrics.UncappedTarget),
+			}
+		}
+	}
+
+	return nil
+}
+
+// listVPAs returns the VerticalPodAutoscalers of the namespace, listed once per namespace.
+func (m *vpaProvider) listVPAs(ctx context.Context, namespace string) []vpav1.VerticalPodAutoscaler {
+	if m.vpas != nil && m.namespace == namespace {
+		return m.vpas
+	}
+
+	m.vpas, m.namespace = []vpav1.VerticalPodAutoscaler{}, namespace
+
+	vpaList, err := m.vpaClient.AutoscalingV1().VerticalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return m.vpas
+	}
+
+	m.vpas = append(m.vpas, vpaList.Items...)
+
+	return m.vpas
+}
+
+func resourceValues(list v1.ResourceList) resources.ResourceValues {
+	var values resources.ResourceValues
+
+	if cpu, ok := list[v1.ResourceCPU]; ok {
+		values.CPU = cpu.MilliValue()
+	}
+
+	if mem, ok := list[v1.ResourceMemory]; ok {
+		values.Mem = mem.Value()
+	}
+
+	return values
+}
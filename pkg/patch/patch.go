@@ -51,7 +51,7 @@ func ApplyPatchesToYaml(yamlText string, res resources.ResourceRecommendation) (
 
 	if len(workloadPaths) == 0 {
 		switch {
-		case res.Release == res.Name && values["resources"] != nil:
+		case res.Release == res.Name && hasKey(values, "resources"):
 			containerName := res.Container
 			if containerName == res.Chart {
 				containerName = ""
@@ -65,7 +65,7 @@ func ApplyPatchesToYaml(yamlText string, res resources.ResourceRecommendation) (
 			}
 		case values[toCamelCase(workloadName)] != nil:
 			if workloadData, ok := values[toCamelCase(workloadName)].(map[string]any); ok {
-				if _, ok := workloadData["resources"].(map[string]any); ok {
+				if hasKey(workloadData, "resources") {
 					workloadPaths = append(workloadPaths, WorkloadPath{
 						Section:  "",
 						Workload: toCamelCase(workloadName),
@@ -136,6 +136,15 @@ func findWorkloadPaths(values map[string]any, workloadName string) []WorkloadPat
 func applyResourcePatchesToPath(yamlText string, path WorkloadPath, rec resources.ResourceRecommendation) (string, error) {
 	var errs error
 
+	if rec.RemoveCPULimit {
+		newText, err := deleteValue(yamlText, path, "limits", "cpu")
+		if err != nil {
+			errs = multierr.Append(errs, err)
+		} else {
+			yamlText = newText
+		}
+	}
+
 	if rec.RecommendedCPULimit > 0 {
 		newText, err := applyValuePatch(yamlText, path, "limits", "cpu", formatCPUForYaml(rec.RecommendedCPULimit))
 		if err != nil {
@@ -172,9 +181,116 @@ func applyResourcePatchesToPath(yamlText string, path WorkloadPath, rec resource
 		}
 	}
 
+	workloadPath := WorkloadPath{Section: path.Section, Workload: path.Workload}
+
+	if rec.RecommendedCPUUtilization > 0 {
+		newText, err := applyExistingValuePatch(yamlText, workloadPath, fmt.Sprintf("%d", rec.RecommendedCPUUtilization),
+			"autoscaling", "targetCPUUtilizationPercentage")
+		if err != nil {
+			errs = multierr.Append(errs, err)
+		} else {
+			yamlText = newText
+		}
+	}
+
+	// The replicas of an autoscaled workload are set by its autoscaler.
+	if rec.RecommendedReplicas > 0 && existingValue(yamlText, workloadPath, "autoscaling", "enabled") != "true" {
+		newText, err := applyExistingValuePatch(yamlText, workloadPath, fmt.Sprintf("%d", rec.RecommendedReplicas), "replicaCount")
+		if err != nil {
+			errs = multierr.Append(errs, err)
+		} else {
+			yamlText = newText
+		}
+	}
+
 	return yamlText, errs
 }
 
+// applyExistingValuePatch sets the value of the nested key of the workload values, e.g. "autoscaling", "targetCPUUtilizationPercentage".
+// The values are left untouched when the key does not exist, charts do not share a convention for these keys.
+func applyExistingValuePatch(yamlText string, path WorkloadPath, newValue string, keys ...string) (string, error) {
+	lines := strings.Split(yamlText, "\n")
+
+	line, err := findExistingKey(lines, path, keys...)
+	if err != nil || line < 0 {
+		return yamlText, err
+	}
+
+	lines[line] = replaceValue(lines[line], keys[len(keys)-1], newValue)
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// existingValue returns the value of the nested key of the workload values without quotes and comments,
+// empty when the key does not exist.
+func existingValue(yamlText string, path WorkloadPath, keys ...string) string {
+	lines := strings.Split(yamlText, "\n")
+
+	line, err := findExistingKey(lines, path, keys...)
+	if err != nil || line < 0 {
+		return ""
+	}
+
+	_, value, _ := strings.Cut(strings.TrimSuffix(lines[line], inlineComment(lines[line])), ":")
+
+	return strings.Trim(strings.TrimSpace(value), `"'`)
+}
+
+// findExistingKey returns the line of the nested key of the workload values, -1 when the key does not exist.
+func findExistingKey(lines []string, path WorkloadPath, keys ...string) (int, error) {
+	// Top-level values are searched in the whole document.
+	line, indent := -1, -1
+
+	if path.Section != "" || path.Workload != "" {
+		var err error
+
+		line, indent, err = findTargetLocation(lines, path)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	for _, key := range keys {
+		line = findChildKey(lines, line, indent, key)
+		if line < 0 {
+			return -1, nil
+		}
+
+		indent = len(lines[line]) - len(strings.TrimLeft(lines[line], " \t"))
+	}
+
+	return line, nil
+}
+
+// findChildKey returns the line of the key among the direct children of the start line.
+func findChildKey(lines []string, startLine, baseIndent int, key string) int {
+	childIndent := -1
+
+	for i := startLine + 1; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if indent <= baseIndent {
+			break
+		}
+
+		if childIndent < 0 {
+			childIndent = indent
+		}
+
+		if indent == childIndent && strings.HasPrefix(trimmed, key+":") {
+			return i
+		}
+	}
+
+	return -1
+}
+
 func applyValuePatch(yamlText string, path WorkloadPath, resourceType, resource, newValue string) (string, error) {
 	lines := strings.Split(yamlText, "\n")
 
@@ -184,12 +300,18 @@ func applyValuePatch(yamlText string, path WorkloadPath, resourceType, resource,
 	}
 
 	resourcesLine, resourcesIndent := findResourcesSection(lines, targetLine, targetIndent)
+	if resourcesLine >= 0 {
+		lines[resourcesLine] = expandEmptyMapping(lines[resourcesLine])
+	}
+
 	resourceTypeLine, resourceTypeIndent := findResourceTypeSection(lines, resourcesLine, resourcesIndent, resourceType)
+	if resourceTypeLine >= 0 {
+		lines[resourceTypeLine] = expandEmptyMapping(lines[resourceTypeLine])
+	}
 	resourceLine := findResourceLine(lines, resourceTypeLine, resourceTypeIndent, resource)
 
 	if resourceLine >= 0 {
-		indent := len(lines[resourceLine]) - len(strings.TrimLeft(lines[resourceLine], " \t"))
-		lines[resourceLine] = strings.Repeat(" ", indent) + resource + ": " + newValue
+		lines[resourceLine] = replaceValue(lines[resourceLine], resource, newValue)
 	} else {
 		lines = addMissingResourceStructure(lines, targetLine, targetIndent, resourcesLine, resourcesIndent,
 			resourceTypeLine, resourceTypeIndent, resourceType, resource, newValue)
@@ -198,6 +320,48 @@ func applyValuePatch(yamlText string, path WorkloadPath, resourceType, resource,
 	return strings.Join(lines, "\n"), nil
 }
 
+// deleteValue removes the resource key, and the resource type section when it becomes empty.
+func deleteValue(yamlText string, path WorkloadPath, resourceType, resource string) (string, error) {
+	lines := strings.Split(yamlText, "\n")
+
+	targetLine, targetIndent, err := findTargetLocation(lines, path)
+	if err != nil {
+		return "", err
+	}
+
+	resourcesLine, resourcesIndent := findResourcesSection(lines, targetLine, targetIndent)
+	resourceTypeLine, resourceTypeIndent := findResourceTypeSection(lines, resourcesLine, resourcesIndent, resourceType)
+
+	resourceLine := findResourceLine(lines, resourceTypeLine, resourceTypeIndent, resource)
+	if resourceLine < 0 {
+		return yamlText, nil
+	}
+
+	lines = append(lines[:resourceLine], lines[resourceLine+1:]...)
+
+	if !hasChildren(lines, resourceTypeLine, resourceTypeIndent) {
+		lines = append(lines[:resourceTypeLine], lines[resourceTypeLine+1:]...)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func hasChildren(lines []string, startLine, baseIndent int) bool {
+	for i := startLine + 1; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		return indent > baseIndent
+	}
+
+	return false
+}
+
 func findTargetLocation(lines []string, path WorkloadPath) (int, int, error) {
 	sectionFound := path.Section == ""
 	workloadFound := path.Workload == ""
@@ -44,6 +44,56 @@ componentName:
       memory: 768Mi
 `
 
+	emptyResourcesYAML = `
+image: nginx
+resources: {}
+
+worker:
+  image: busybox
+  resources: # set by the user
+  nodeSelector: {}
+`
+
+	cpuLimitYAML = `
+resources:
+  limits:
+    cpu: 1
+  requests:
+    cpu: 500m
+    memory: 256Mi
+`
+
+	autoscalingYAML = `
+replicaCount: 2
+autoscaling:
+  enabled: true
+  minReplicas: 2
+  targetCPUUtilizationPercentage: 80
+resources:
+  requests:
+    cpu: 100m
+`
+
+	manualScalingYAML = `
+replicaCount: 2
+autoscaling:
+  enabled: false
+  targetCPUUtilizationPercentage: 80
+resources:
+  requests:
+    cpu: 100m
+`
+
+	commentedAutoscalingYAML = `
+replicaCount: 2 # set by the operator
+autoscaling:
+  enabled: false # scaled by hand
+  targetCPUUtilizationPercentage: 80 # "percent" of the requests
+resources:
+  requests:
+    cpu: 100m # one core is 1000m
+`
+
 	simpleServiceYAML = `
 someOtherField: someValue
 services:
@@ -154,6 +204,163 @@ componentName:
     requests:
       cpu: 100m
       memory: 256Mi
+`,
+		},
+		{
+			name: "empty resources patch",
+			yaml: emptyResourcesYAML,
+			resources: resources.ResourceRecommendation{
+				Release:               "app",
+				Name:                  "app",
+				RecommendedCPURequest: 100,
+				RecommendedMemRequest: 256 * 1024 * 1024,
+				RecommendedCPULimit:   200,
+				RecommendedMemLimit:   512 * 1024 * 1024,
+			},
+			expect: `
+image: nginx
+resources:
+  limits:
+    cpu: 200m
+    memory: 512Mi
+  requests:
+    cpu: 100m
+    memory: 256Mi
+
+worker:
+  image: busybox
+  resources: # set by the user
+  nodeSelector: {}
+`,
+		},
+		{
+			name: "null resources patch",
+			yaml: emptyResourcesYAML,
+			resources: resources.ResourceRecommendation{
+				Release:               "app",
+				Name:                  "app-worker",
+				RecommendedCPURequest: 100,
+				RecommendedMemRequest: 256 * 1024 * 1024,
+			},
+			expect: `
+image: nginx
+resources: {}
+
+worker:
+  image: busybox
+  resources: # set by the user
+    requests:
+      cpu: 100m
+      memory: 256Mi
+  nodeSelector: {}
+`,
+		},
+		{
+			name: "remove cpu limit patch",
+			yaml: commonYAML,
+			resources: resources.ResourceRecommendation{
+				Release:        "common",
+				Name:           "component-name",
+				RemoveCPULimit: true,
+			},
+			expect: `
+someOtherField: someValue
+resources:
+  requests:
+    cpu: 50m
+    memory: 128Mi
+
+componentName:
+  otherField: someValue
+  resources:
+    limits:
+      memory: 1Gi
+    requests:
+      cpu: 100m
+      memory: 768Mi
+`,
+		},
+		{
+			name: "remove empty limits patch",
+			yaml: cpuLimitYAML,
+			resources: resources.ResourceRecommendation{
+				Release:        "app",
+				Name:           "app",
+				RemoveCPULimit: true,
+			},
+			expect: `
+resources:
+  requests:
+    cpu: 500m
+    memory: 256Mi
+`,
+		},
+		{
+			name: "autoscaling target patch",
+			yaml: autoscalingYAML,
+			resources: resources.ResourceRecommendation{
+				Release:                   "app",
+				Name:                      "app",
+				RecommendedCPURequest:     200,
+				RecommendedCPUUtilization: 40,
+			},
+			expect: `
+replicaCount: 2
+autoscaling:
+  enabled: true
+  minReplicas: 2
+  targetCPUUtilizationPercentage: 40
+resources:
+  requests:
+    cpu: 200m
+`,
+		},
+		{
+			name: "replica count patch",
+			yaml: manualScalingYAML,
+			resources: resources.ResourceRecommendation{
+				Release:             "app",
+				Name:                "app",
+				RecommendedReplicas: 5,
+			},
+			expect: `
+replicaCount: 5
+autoscaling:
+  enabled: false
+  targetCPUUtilizationPercentage: 80
+resources:
+  requests:
+    cpu: 100m
+`,
+		},
+		{
+			name: "replica count kept with autoscaling enabled",
+			yaml: autoscalingYAML,
+			resources: resources.ResourceRecommendation{
+				Release:             "app",
+				Name:                "app",
+				RecommendedReplicas: 5,
+			},
+			expect: autoscalingYAML,
+		},
+		{
+			name: "inline comments are kept",
+			yaml: commentedAutoscalingYAML,
+			resources: resources.ResourceRecommendation{
+				Release:                   "app",
+				Name:                      "app",
+				RecommendedCPURequest:     200,
+				RecommendedCPUUtilization: 40,
+				RecommendedReplicas:       5,
+			},
+			expect: `
+replicaCount: 5 # set by the operator
+autoscaling:
+  enabled: false # scaled by hand
+  targetCPUUtilizationPercentage: 40 # "percent" of the requests
+resources:
+  requests:
+    cpu: 200m # one core is 1000m
 `,
 		},
 		{
@@ -53,3 +53,63 @@ func toCamelCase(input string) string {
 
 	return strings.Join(parts, "")
 }
+
+func hasKey(values map[string]any, key string) bool {
+	_, ok := values[key]
+
+	return ok
+}
+
+// expandEmptyMapping turns an empty mapping line, such as "resources: {}" or "resources: null",
+// into a block mapping key, so nested keys can be inserted below it.
+func expandEmptyMapping(line string) string {
+	key, value, found := strings.Cut(line, ":")
+	if !found {
+		return line
+	}
+
+	value, comment, _ := strings.Cut(value, "#")
+
+	switch strings.TrimSpace(value) {
+	case "{}", "null", "~":
+		if comment = strings.TrimSpace(comment); comment != "" {
+			return key + ": # " + comment
+		}
+
+		return key + ":"
+	}
+
+	return line
+}
+
+// replaceValue sets the value of the key line, keeping its indentation and trailing comment.
+func replaceValue(line, key, value string) string {
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	result := strings.Repeat(" ", indent) + key + ": " + value
+
+	if comment := inlineComment(line); comment != "" {
+		result += " " + comment
+	}
+
+	return result
+}
+
+// inlineComment returns the comment at the end of the line, a # preceded by whitespace outside of quotes.
+func inlineComment(line string) string {
+	var quote rune
+
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && i > 0 && (line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[i:]
+		}
+	}
+
+	return ""
+}
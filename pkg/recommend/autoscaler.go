@@ -0,0 +1,89 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+const (
+	// MinCPUUtilizationTarget is the lowest recommended CPU utilization target of autoscalers, in percent.
+	MinCPUUtilizationTarget = 10
+	// MaxCPUUtilizationTarget is the highest recommended CPU utilization target of autoscalers, in percent.
+	MaxCPUUtilizationTarget = 100
+)
+
+// recommendAutoscalerTargets recommends the CPU utilization targets of the autoscalers,
+// so the workloads scale at the same CPU usage after their CPU requests change.
+// The utilization is relative to the requests: newTarget = oldTarget × oldRequests / newRequests.
+func recommendAutoscalerTargets(res []resources.ResourceInfo, recs []resources.ResourceRecommendation) {
+	for i := range recs {
+		rec := &recs[i]
+		if rec.RecommendedCPURequest == 0 {
+			continue
+		}
+
+		var (
+			a                   *resources.Autoscaler
+			oldRequest, request int64
+		)
+
+		for _, r := range res {
+			if r.Kind != rec.Kind || r.Name != rec.Name || r.Autoscaler == nil || r.Autoscaler.CPUUtilization == 0 {
+				continue
+			}
+
+			a = r.Autoscaler
+			if a.CPUContainer != "" && a.CPUContainer != r.Container {
+				continue
+			}
+
+			oldRequest += r.CPURequest
+			request += effective(containerRecommendation(recs, r).RecommendedCPURequest, r.CPURequest)
+		}
+
+		if a == nil || (a.CPUContainer != "" && a.CPUContainer != rec.Container) || oldRequest == 0 || request == oldRequest {
+			continue
+		}
+
+		target := int32(math.Round(float64(a.CPUUtilization) * float64(oldRequest) / float64(request)))
+		target = min(max(target, MinCPUUtilizationTarget), MaxCPUUtilizationTarget)
+
+		if target == a.CPUUtilization {
+			continue
+		}
+
+		rec.CurrentCPUUtilization = a.CPUUtilization
+		rec.RecommendedCPUUtilization = target
+		rec.Notes = append(rec.Notes, fmt.Sprintf("%s %s CPU target %d%% -> %d%% keeps the scaling threshold",
+			a.Kind, a.Name, a.CPUUtilization, target))
+	}
+}
+
+// containerRecommendation returns the recommendation of the container, or an empty one.
+func containerRecommendation(recs []resources.ResourceRecommendation, r resources.ResourceInfo) resources.ResourceRecommendation {
+	for _, rec := range recs {
+		if rec.Kind == r.Kind && rec.Name == r.Name && rec.Container == r.Container {
+			return rec
+		}
+	}
+
+	return resources.ResourceRecommendation{}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+
+	"github.com/sergelogvinov/helm-resources/pkg/cluster"
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+// QuotaHeadroom is the headroom of a ResourceQuota resource after applying the recommendations.
+type QuotaHeadroom struct {
+	Quota    string
+	Resource string
+	Hard     int64
+	Used     int64
+	// Change is the difference of the recommended and current values, multiplied by replicas
+	Change int64
+}
+
+// Headroom returns the quota left after applying the recommendations, negative when the quota is exceeded.
+func (h QuotaHeadroom) Headroom() int64 {
+	return h.Hard - h.Used - h.Change
+}
+
+// applyLimitRange clamps the recommendation to the LimitRange of the namespace,
+// and keeps CPU limits the namespace requires.
+func applyLimitRange(rec *resources.ResourceRecommendation, c *cluster.Constraints) {
+	if c == nil {
+		return
+	}
+
+	if rec.RemoveCPULimit && (c.Limits.CPU.Max > 0 || c.RequiresCPULimit()) {
+		rec.RemoveCPULimit = false
+		rec.Notes = append(rec.Notes, "CPU limit kept, the namespace LimitRange or ResourceQuota requires it")
+	}
+
+	for _, r := range []struct {
+		name                                 string
+		recommendedRequest, recommendedLimit *int64
+		currentRequest, currentLimit         int64
+		removeLimit                          bool
+		limits                               cluster.ResourceLimits
+		format                               func(int64) string
+	}{
+		{"CPU", &rec.RecommendedCPURequest, &rec.RecommendedCPULimit, rec.CurrentCPURequest, rec.CurrentCPULimit, rec.RemoveCPULimit, c.Limits.CPU, formatMilliCores},
+		{"memory", &rec.RecommendedMemRequest, &rec.RecommendedMemLimit, rec.CurrentMemRequest, rec.CurrentMemLimit, false, c.Limits.Memory, resources.FormatMemory},
+	} {
+		clampToRange(rec, r.name+" request", r.recommendedRequest, r.currentRequest, r.limits, r.format)
+
+		if r.removeLimit {
+			continue
+		}
+
+		clampToRange(rec, r.name+" limit", r.recommendedLimit, r.currentLimit, r.limits, r.format)
+
+		request := effective(*r.recommendedRequest, r.currentRequest)
+		limit := effective(*r.recommendedLimit, r.currentLimit)
+
+		if r.limits.MaxLimitRequestRatio > 0 && request > 0 && limit > 0 &&
+			float64(limit) > float64(request)*r.limits.MaxLimitRequestRatio {
+			limit = int64(float64(request) * r.limits.MaxLimitRequestRatio)
+
+			setRecommended(r.recommendedLimit, r.currentLimit, limit)
+			rec.Notes = append(rec.Notes, fmt.Sprintf("%s limit lowered to %s by the LimitRange limit to request ratio %g",
+				r.name, r.format(limit), r.limits.MaxLimitRequestRatio))
+		}
+	}
+}
+
+func clampToRange(rec *resources.ResourceRecommendation, name string, recommended *int64, current int64, limits cluster.ResourceLimits, format func(int64) string) {
+	v := effective(*recommended, current)
+
+	switch {
+	case v == 0:
+	case limits.Min > 0 && v < limits.Min:
+		setRecommended(recommended, current, limits.Min)
+		rec.Notes = append(rec.Notes, fmt.Sprintf("%s %s raised to the LimitRange minimum %s", name, format(v), format(limits.Min)))
+	case limits.Max > 0 && v > limits.Max:
+		setRecommended(recommended, current, limits.Max)
+		rec.Notes = append(rec.Notes, fmt.Sprintf("%s %s lowered to the LimitRange maximum %s", name, format(v), format(limits.Max)))
+	}
+}
+
+// setRecommended sets the recommended value, 0 when it keeps the current value.
+func setRecommended(recommended *int64, current, v int64) {
+	if v == current {
+		*recommended = 0
+	} else {
+		*recommended = v
+	}
+}
+
+// CheckQuotas computes the ResourceQuota headroom after applying the recommendations to all replicas,
+// and flags the recommendations which increase an exceeded quota.
+func CheckQuotas(res []resources.ResourceInfo, recs []resources.ResourceRecommendation, quotas []cluster.Quota) []QuotaHeadroom {
+	type container struct {
+		kind, name, container string
+	}
+
+	replicas := make(map[container]int64, len(res))
+	for _, r := range res {
+		replicas[container{r.Kind, r.Name, r.Container}] = replicaCount(r.Replicas)
+	}
+
+	changes := make([]map[string]int64, len(recs))
+	total := map[string]int64{}
+
+	for i, rec := range recs {
+		n := replicas[container{rec.Kind, rec.Name, rec.Container}]
+		if n == 0 {
+			n = 1
+		}
+
+		newN := n
+		if rec.RecommendedReplicas > 0 {
+			newN = int64(rec.RecommendedReplicas)
+		}
+
+		changes[i] = map[string]int64{
+			cluster.QuotaRequestsCPU:    change(rec.RecommendedCPURequest, rec.CurrentCPURequest, false, n, newN),
+			cluster.QuotaRequestsMemory: change(rec.RecommendedMemRequest, rec.CurrentMemRequest, false, n, newN),
+			cluster.QuotaLimitsCPU:      change(rec.RecommendedCPULimit, rec.CurrentCPULimit, rec.RemoveCPULimit, n, newN),
+			cluster.QuotaLimitsMemory:   change(rec.RecommendedMemLimit, rec.CurrentMemLimit, false, n, newN),
+		}
+
+		for name, v := range changes[i] {
+			total[name] += v
+		}
+	}
+
+	var headroom []QuotaHeadroom
+
+	for _, q := range quotas {
+		for _, name := range []string{cluster.QuotaRequestsCPU, cluster.QuotaRequestsMemory, cluster.QuotaLimitsCPU, cluster.QuotaLimitsMemory} {
+			hard, ok := q.Hard[name]
+			if !ok {
+				continue
+			}
+
+			h := QuotaHeadroom{Quota: q.Name, Resource: name, Hard: hard, Used: q.Used[name], Change: total[name]}
+			headroom = append(headroom, h)
+
+			if h.Headroom() >= 0 {
+				continue
+			}
+
+			for i := range recs {
+				if changes[i][name] > 0 {
+					recs[i].Notes = appendUnique(recs[i].Notes, fmt.Sprintf("exceeds the ResourceQuota %s %s", q.Name, name))
+				}
+			}
+		}
+	}
+
+	return headroom
+}
+
+// change returns the difference of the recommended and current values of all replicas.
+func change(recommended, current int64, remove bool, replicas, newReplicas int64) int64 {
+	value := effective(recommended, current)
+	if remove {
+		value = 0
+	}
+
+	return value*newReplicas - current*replicas
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"strconv"
+)
+
+// DefaultDownsizeThreshold is the default usage to request ratio, in percent,
+// below which a container is considered over-provisioned.
+const DefaultDownsizeThreshold = 50
+
+// overProvisioned reports whether the usage is below the threshold percentage of the request.
+func overProvisioned(usage, request int64, threshold int) bool {
+	if request <= 0 || threshold <= 0 {
+		return false
+	}
+
+	return usage*100 < request*int64(threshold)
+}
+
+// downsize returns the lower request and limit for an over-provisioned resource,
+// 0 means the current value should be kept.
+func downsize(currentRequest, recommendedRequest, currentLimit, recommendedLimit int64) (int64, int64) {
+	var request, limit int64
+
+	effectiveRequest := currentRequest
+	if recommendedRequest < currentRequest {
+		request = recommendedRequest
+		effectiveRequest = recommendedRequest
+	}
+
+	if currentLimit > 0 {
+		recommendedLimit = max(recommendedLimit, effectiveRequest)
+		if recommendedLimit < currentLimit {
+			limit = recommendedLimit
+		}
+	}
+
+	return request, limit
+}
+
+// replicaCount parses the replicas of the workload, unknown values count as a single replica.
+func replicaCount(replicas string) int64 {
+	count, err := strconv.ParseInt(replicas, 10, 64)
+	if err != nil || count <= 0 {
+		return 1
+	}
+
+	return count
+}
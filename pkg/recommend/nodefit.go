@@ -0,0 +1,208 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/sergelogvinov/helm-resources/pkg/cluster"
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+// placement is a workload of the release with the pod requests after applying the recommendations.
+type placement struct {
+	kind, name string
+	replicas   int64
+	scheduling *resources.SchedulingConstraints
+	cpu, mem   int64 // pod requests
+	raised     bool
+	// nodes are the indexes of the nodes matching the scheduling constraints
+	nodes    []int
+	unplaced int64
+}
+
+type capacity struct {
+	cpu, mem int64
+}
+
+// CheckNodeFit simulates the placement of the release pods with the recommended requests on the cluster nodes,
+// and flags the recommendations raising requests which would leave pods Pending or require a new node.
+// Pods are placed first-fit decreasing on the free capacity of the nodes without the release pods.
+// Like the scheduler, the check accounts for the requests of the pods only, not for their live usage.
+func CheckNodeFit(namespace string, res []resources.ResourceInfo, recs []resources.ResourceRecommendation, nodes []cluster.Node) {
+	if len(nodes) == 0 {
+		return
+	}
+
+	workloads := placements(res, recs)
+	if !slices.ContainsFunc(workloads, func(w *placement) bool { return w.raised }) {
+		return
+	}
+
+	free := make([]capacity, len(nodes))
+
+	for i, n := range nodes {
+		free[i] = capacity{n.AllocatableCPU - n.RequestedCPU, n.AllocatableMem - n.RequestedMem}
+
+		for _, p := range n.Pods {
+			if p.Namespace != namespace {
+				continue
+			}
+
+			for _, w := range workloads {
+				if resources.PodBelongsToWorkload(p.Name, w.kind, w.name) {
+					free[i].cpu += p.CPU
+					free[i].mem += p.Mem
+
+					break
+				}
+			}
+		}
+	}
+
+	var pods []*placement
+
+	for _, w := range workloads {
+		for i := range nodes {
+			if nodes[i].Schedulable(w.scheduling) {
+				w.nodes = append(w.nodes, i)
+			}
+		}
+
+		if w.kind == "DaemonSet" {
+			// DaemonSet pods run on every matching node, they are placed first.
+			for _, i := range w.nodes {
+				if !place(&free[i], w) {
+					w.unplaced++
+				}
+			}
+
+			continue
+		}
+
+		for range w.replicas {
+			pods = append(pods, w)
+		}
+	}
+
+	slices.SortStableFunc(pods, func(a, b *placement) int {
+		return cmp.Or(cmp.Compare(b.mem, a.mem), cmp.Compare(b.cpu, a.cpu))
+	})
+
+	for _, w := range pods {
+		if !firstFit(free, w) {
+			w.unplaced++
+		}
+	}
+
+	for _, w := range workloads {
+		if note := w.note(nodes); w.raised && note != "" {
+			for i := range recs {
+				if recs[i].Kind == w.kind && recs[i].Name == w.name && recs[i].RaisesRequests() {
+					recs[i].Notes = appendUnique(recs[i].Notes, note)
+				}
+			}
+		}
+	}
+}
+
+// firstFit places the pod on the first matching node with enough free capacity.
+func firstFit(free []capacity, w *placement) bool {
+	for _, i := range w.nodes {
+		if place(&free[i], w) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// place reserves the pod requests on the node when they fit.
+func place(free *capacity, w *placement) bool {
+	if free.cpu < w.cpu || free.mem < w.mem {
+		return false
+	}
+
+	free.cpu -= w.cpu
+	free.mem -= w.mem
+
+	return true
+}
+
+func (w *placement) note(nodes []cluster.Node) string {
+	switch {
+	case len(w.nodes) == 0:
+		return "no node matches the node selector, affinity and tolerations, pods would stay Pending"
+	case w.unplaced == 0:
+		return ""
+	case !slices.ContainsFunc(w.nodes, func(i int) bool {
+		return nodes[i].AllocatableCPU >= w.cpu && nodes[i].AllocatableMem >= w.mem
+	}):
+		return fmt.Sprintf("pod requests %s/%s exceed the allocatable of every matching node, pods would stay Pending",
+			formatMilliCores(w.cpu), resources.FormatMemory(w.mem))
+	case w.kind == "DaemonSet":
+		return fmt.Sprintf("%d node(s) have no room by requests for the DaemonSet pod, pods would stay Pending", w.unplaced)
+	}
+
+	return fmt.Sprintf("%d of %d pod(s) do not fit on the current nodes by requests, a new node is required", w.unplaced, w.replicas)
+}
+
+// placements returns the workloads of the release with the pod requests after applying the recommendations.
+// CronJobs are skipped, their pods do not run all the time, as well as workloads with unknown scheduling constraints.
+func placements(res []resources.ResourceInfo, recs []resources.ResourceRecommendation) []*placement {
+	var workloads []*placement
+
+	for _, r := range res {
+		if r.Kind == "CronJob" || r.Scheduling == nil {
+			continue
+		}
+
+		i := slices.IndexFunc(workloads, func(w *placement) bool { return w.kind == r.Kind && w.name == r.Name })
+		if i < 0 {
+			i = len(workloads)
+			workloads = append(workloads, &placement{
+				kind:       r.Kind,
+				name:       r.Name,
+				replicas:   replicaCount(r.Replicas),
+				scheduling: r.Scheduling,
+			})
+		}
+
+		w := workloads[i]
+		cpu, mem := r.CPURequest, r.MemRequest
+
+		if j := slices.IndexFunc(recs, func(rec resources.ResourceRecommendation) bool {
+			return rec.Kind == r.Kind && rec.Name == r.Name && rec.Container == r.Container
+		}); j >= 0 {
+			cpu = effective(recs[j].RecommendedCPURequest, cpu)
+			mem = effective(recs[j].RecommendedMemRequest, mem)
+			w.raised = w.raised || cpu > r.CPURequest || mem > r.MemRequest
+
+			if recs[j].RecommendedReplicas > 0 {
+				w.replicas = int64(recs[j].RecommendedReplicas)
+				w.raised = true
+			}
+		}
+
+		w.cpu += cpu
+		w.mem += mem
+	}
+
+	return workloads
+}
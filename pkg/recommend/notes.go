@@ -0,0 +1,99 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+// eventNotes explains the scheduling and eviction signals attached to the container.
+func eventNotes(r resources.ResourceInfo) []string {
+	var (
+		notes                   []string
+		pending, evicted        []string
+		pendingRes, pressureRes []string
+	)
+
+	for _, e := range r.Events {
+		switch e.Reason {
+		case resources.EventFailedScheduling:
+			pending = appendUnique(pending, e.Pod)
+			for _, name := range e.Resources {
+				pendingRes = appendUnique(pendingRes, name)
+			}
+		case resources.EventEvicted:
+			evicted = appendUnique(evicted, e.Pod)
+			if e.NodePressure {
+				for _, name := range e.Resources {
+					pressureRes = appendUnique(pressureRes, name)
+				}
+			}
+		}
+	}
+
+	if len(pending) > 0 {
+		notes = append(notes, fmt.Sprintf("%d pod(s) pending, requests do not fit on any node: insufficient %s",
+			len(pending), strings.Join(pendingRes, ", ")))
+	}
+
+	if len(evicted) > 0 {
+		note := fmt.Sprintf("%d pod(s) evicted", len(evicted))
+		if len(pressureRes) > 0 {
+			note += fmt.Sprintf(" under node %s pressure", strings.Join(pressureRes, ", "))
+
+			if slices.Contains(pressureRes, "memory") && r.MemRequest > 0 && r.MemUsage > r.MemRequest {
+				note += ", memory usage above request makes the pod the first eviction candidate"
+			}
+		}
+
+		notes = append(notes, note)
+	}
+
+	return notes
+}
+
+// missingRequestsNotes explains recommendations for containers without requests.
+func missingRequestsNotes(r resources.ResourceInfo) []string {
+	switch {
+	case r.CPURequest == 0 && r.MemRequest == 0 && r.CPULimit == 0 && r.MemLimit == 0:
+		return []string{"no requests and limits set, the container runs as BestEffort"}
+	case r.CPURequest == 0 && r.MemRequest == 0:
+		return []string{"no requests set"}
+	case r.CPURequest == 0:
+		return []string{"no CPU request set"}
+	case r.MemRequest == 0:
+		return []string{"no memory request set"}
+	}
+
+	return nil
+}
+
+func appendUnique(list []string, value string) []string {
+	if slices.Contains(list, value) {
+		return list
+	}
+
+	return append(list, value)
+}
+
+func formatMilliCores(milliCores int64) string {
+	return fmt.Sprintf("%dm", milliCores)
+}
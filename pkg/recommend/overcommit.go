@@ -0,0 +1,129 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/sergelogvinov/helm-resources/pkg/cluster"
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+// OvercommitTopContributors is the number of release containers reported per node.
+const OvercommitTopContributors = 3
+
+// NodeOvercommit is the overcommit of a node hosting release pods.
+type NodeOvercommit struct {
+	Node           string
+	AllocatableCPU int64 // milli-cores
+	AllocatableMem int64 // bytes
+	RequestedCPU   int64 // milli-cores
+	RequestedMem   int64 // bytes
+	LimitedCPU     int64 // milli-cores
+	LimitedMem     int64 // bytes
+	// Contributors are the release containers with the largest limits over requests on the node
+	Contributors []OvercommitContributor
+}
+
+// OvercommitContributor is a release container contributing to the node overcommit.
+type OvercommitContributor struct {
+	Kind      string
+	Name      string
+	Container string
+	Pods      int
+	// Limits over requests of the container, summed over its pods on the node
+	CPU int64 // milli-cores
+	Mem int64 // bytes
+}
+
+// CPURatio returns the sum of CPU limits to the allocatable CPU of the node.
+func (n NodeOvercommit) CPURatio() float64 {
+	return ratio(n.LimitedCPU, n.AllocatableCPU)
+}
+
+// MemRatio returns the sum of memory limits to the allocatable memory of the node.
+func (n NodeOvercommit) MemRatio() float64 {
+	return ratio(n.LimitedMem, n.AllocatableMem)
+}
+
+// Overcommit returns the overcommit of the nodes hosting release pods, the most overcommitted memory first.
+func Overcommit(namespace string, res []resources.ResourceInfo, nodes []cluster.Node) []NodeOvercommit {
+	var report []NodeOvercommit
+
+	for _, n := range nodes {
+		var contributors []OvercommitContributor
+
+		for _, p := range n.Pods {
+			if p.Namespace != namespace {
+				continue
+			}
+
+			for _, r := range res {
+				if !resources.PodBelongsToWorkload(p.Name, r.Kind, r.Name) {
+					continue
+				}
+
+				i := slices.IndexFunc(contributors, func(c OvercommitContributor) bool {
+					return c.Kind == r.Kind && c.Name == r.Name && c.Container == r.Container
+				})
+				if i < 0 {
+					i = len(contributors)
+					contributors = append(contributors, OvercommitContributor{Kind: r.Kind, Name: r.Name, Container: r.Container})
+				}
+
+				contributors[i].Pods++
+				contributors[i].CPU += max(r.CPULimit-r.CPURequest, 0)
+				contributors[i].Mem += max(r.MemLimit-r.MemRequest, 0)
+			}
+		}
+
+		if len(contributors) == 0 {
+			continue
+		}
+
+		contributors = slices.DeleteFunc(contributors, func(c OvercommitContributor) bool { return c.CPU == 0 && c.Mem == 0 })
+		slices.SortStableFunc(contributors, func(a, b OvercommitContributor) int {
+			return cmp.Or(cmp.Compare(b.Mem, a.Mem), cmp.Compare(b.CPU, a.CPU))
+		})
+
+		report = append(report, NodeOvercommit{
+			Node:           n.Name,
+			AllocatableCPU: n.AllocatableCPU,
+			AllocatableMem: n.AllocatableMem,
+			RequestedCPU:   n.RequestedCPU,
+			RequestedMem:   n.RequestedMem,
+			LimitedCPU:     n.LimitedCPU,
+			LimitedMem:     n.LimitedMem,
+			Contributors:   contributors[:min(len(contributors), OvercommitTopContributors)],
+		})
+	}
+
+	slices.SortStableFunc(report, func(a, b NodeOvercommit) int {
+		return cmp.Or(cmp.Compare(b.MemRatio(), a.MemRatio()), cmp.Compare(b.CPURatio(), a.CPURatio()))
+	})
+
+	return report
+}
+
+func ratio(value, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+
+	return float64(value) / float64(total)
+}
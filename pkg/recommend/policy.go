@@ -0,0 +1,228 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Policy defines how recommendations are sized.
+// Empty fields fall back to the built-in defaults.
+type Policy struct {
+	CPU    ResourcePolicy `json:"cpu,omitempty"`
+	Memory ResourcePolicy `json:"memory,omitempty"`
+	// Overrides are applied in order to the matching containers, later overrides win.
+	Overrides []PolicyOverride `json:"overrides,omitempty"`
+	// TargetQoS is the QoS target of all containers, see TargetQoSNames.
+	TargetQoS string `json:"targetQoS,omitempty"`
+	// Strategies are custom sizing strategies by name, in addition to the built-in ones.
+	Strategies map[string]Strategy `json:"strategies,omitempty"`
+}
+
+// ResourcePolicy defines the headroom, minimum, rounding steps and hard caps of a resource.
+type ResourcePolicy struct {
+	// RequestMultiplier is the headroom of requests over usage, e.g. 1.2 for 20%.
+	RequestMultiplier float64 `json:"requestMultiplier,omitempty"`
+	// LimitMultiplier is the headroom of limits over usage, e.g. 2.0 for 100%.
+	LimitMultiplier float64 `json:"limitMultiplier,omitempty"`
+	// Minimum is the lowest recommended value.
+	Minimum *resource.Quantity `json:"minimum,omitempty"`
+	// Step is the rounding step of recommended values.
+	Step *resource.Quantity `json:"step,omitempty"`
+	// LargeStep is the rounding step of values from LargeStepFrom.
+	LargeStep     *resource.Quantity `json:"largeStep,omitempty"`
+	LargeStepFrom *resource.Quantity `json:"largeStepFrom,omitempty"`
+	// MaxRequest and MaxLimit are hard caps, recommendations above them are capped and flagged for escalation.
+	MaxRequest *resource.Quantity `json:"maxRequest,omitempty"`
+	MaxLimit   *resource.Quantity `json:"maxLimit,omitempty"`
+}
+
+// PolicyOverride changes the policy of the containers matching the selector.
+type PolicyOverride struct {
+	Match     PolicyMatch    `json:"match"`
+	CPU       ResourcePolicy `json:"cpu,omitempty"`
+	Memory    ResourcePolicy `json:"memory,omitempty"`
+	TargetQoS string         `json:"targetQoS,omitempty"`
+}
+
+// PolicyMatch selects containers by workload kind, workload name, container name and labels.
+// Names support shell patterns, e.g. "pg-*". Empty fields match any container.
+type PolicyMatch struct {
+	Kind      string            `json:"kind,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Container string            `json:"container,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// Selector is a label selector, e.g. "tier in (db,cache),severity=critical".
+	Selector string `json:"selector,omitempty"`
+}
+
+// Validate checks the policy for invalid values.
+func (p *Policy) Validate() error {
+	if err := p.CPU.validate("cpu"); err != nil {
+		return err
+	}
+
+	if err := p.Memory.validate("memory"); err != nil {
+		return err
+	}
+
+	if err := validateTargetQoS("targetQoS", p.TargetQoS); err != nil {
+		return err
+	}
+
+	for name, strategy := range p.Strategies {
+		if err := strategy.validate(name); err != nil {
+			return err
+		}
+	}
+
+	for i, o := range p.Overrides {
+		if o.Match.Selector != "" {
+			if _, err := labels.Parse(o.Match.Selector); err != nil {
+				return fmt.Errorf("overrides[%d]: invalid selector: %w", i, err)
+			}
+		}
+
+		if o.Match.Name != "" {
+			if _, err := path.Match(o.Match.Name, ""); err != nil {
+				return fmt.Errorf("overrides[%d]: invalid name pattern: %w", i, err)
+			}
+		}
+
+		if err := o.CPU.validate(fmt.Sprintf("overrides[%d].cpu", i)); err != nil {
+			return err
+		}
+
+		if err := o.Memory.validate(fmt.Sprintf("overrides[%d].memory", i)); err != nil {
+			return err
+		}
+
+		if err := validateTargetQoS(fmt.Sprintf("overrides[%d].targetQoS", i), o.TargetQoS); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p ResourcePolicy) validate(name string) error {
+	if p.RequestMultiplier < 0 || p.LimitMultiplier < 0 {
+		return fmt.Errorf("%s: multipliers must be positive", name)
+	}
+
+	for _, q := range []*resource.Quantity{p.Minimum, p.Step, p.LargeStep, p.LargeStepFrom, p.MaxRequest, p.MaxLimit} {
+		if q != nil && q.Sign() < 0 {
+			return fmt.Errorf("%s: quantities must be positive", name)
+		}
+	}
+
+	return nil
+}
+
+// sizingFor returns the CPU and memory sizing of the container.
+func (p *Policy) sizingFor(r resources.ResourceInfo) (sizing, sizing) {
+	cpu, mem := defaultCPUSizing(), defaultMemorySizing()
+
+	if p == nil {
+		return cpu, mem
+	}
+
+	cpu = cpu.merge(p.CPU, milliValue)
+	mem = mem.merge(p.Memory, value)
+
+	for _, o := range p.Overrides {
+		if o.Match.matches(r) {
+			cpu = cpu.merge(o.CPU, milliValue)
+			mem = mem.merge(o.Memory, value)
+		}
+	}
+
+	return cpu, mem
+}
+
+func (m PolicyMatch) matches(r resources.ResourceInfo) bool {
+	if m.Kind != "" && m.Kind != r.Kind {
+		return false
+	}
+
+	if m.Name != "" {
+		if ok, err := path.Match(m.Name, r.Name); err != nil || !ok {
+			return false
+		}
+	}
+
+	if m.Container != "" {
+		if ok, err := path.Match(m.Container, r.Container); err != nil || !ok {
+			return false
+		}
+	}
+
+	if len(m.Labels) > 0 && !labels.SelectorFromSet(m.Labels).Matches(labels.Set(r.WorkloadLabels)) {
+		return false
+	}
+
+	if m.Selector != "" {
+		selector, err := labels.Parse(m.Selector)
+		if err != nil || !selector.Matches(labels.Set(r.WorkloadLabels)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s sizing) merge(p ResourcePolicy, quantity func(*resource.Quantity) int64) sizing {
+	if p.RequestMultiplier > 0 {
+		s.requestMultiplier = p.RequestMultiplier
+	}
+
+	if p.LimitMultiplier > 0 {
+		s.limitMultiplier = p.LimitMultiplier
+	}
+
+	for _, f := range []struct {
+		q   *resource.Quantity
+		dst *int64
+	}{
+		{p.Minimum, &s.minimum},
+		{p.Step, &s.step},
+		{p.LargeStep, &s.largeStep},
+		{p.LargeStepFrom, &s.largeStepFrom},
+		{p.MaxRequest, &s.maxRequest},
+		{p.MaxLimit, &s.maxLimit},
+	} {
+		if f.q != nil {
+			*f.dst = quantity(f.q)
+		}
+	}
+
+	return s
+}
+
+func milliValue(q *resource.Quantity) int64 {
+	return q.MilliValue()
+}
+
+func value(q *resource.Quantity) int64 {
+	return q.Value()
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergelogvinov/helm-resources/pkg/recommend"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy recommend.Policy
+		err    string
+	}{
+		{
+			name: "valid policy",
+			policy: recommend.Policy{
+				CPU:       recommend.ResourcePolicy{RequestMultiplier: 1.2, Step: ptr.To(resource.MustParse("50m")), MaxRequest: ptr.To(resource.MustParse("4"))},
+				Memory:    recommend.ResourcePolicy{LimitMultiplier: 2, Minimum: ptr.To(resource.MustParse("64Mi"))},
+				TargetQoS: recommend.TargetQoSGuaranteed,
+				Overrides: []recommend.PolicyOverride{
+					{Match: recommend.PolicyMatch{Name: "pg-*", Selector: "tier in (db,cache)"}, TargetQoS: recommend.TargetQoSNoCPULimit},
+				},
+				Strategies: map[string]recommend.Strategy{
+					"p99": {CPURequest: recommend.Estimator{Statistic: "p99"}, MemRequest: recommend.Estimator{Statistic: "max"}},
+				},
+			},
+		},
+		{
+			name:   "negative request multiplier",
+			policy: recommend.Policy{CPU: recommend.ResourcePolicy{RequestMultiplier: -1}},
+			err:    "cpu: multipliers must be positive",
+		},
+		{
+			name: "negative override limit multiplier",
+			policy: recommend.Policy{Overrides: []recommend.PolicyOverride{
+				{Memory: recommend.ResourcePolicy{LimitMultiplier: -0.5}},
+			}},
+			err: "overrides[0].memory: multipliers must be positive",
+		},
+		{
+			name:   "negative step",
+			policy: recommend.Policy{Memory: recommend.ResourcePolicy{Step: ptr.To(resource.MustParse("-128Mi"))}},
+			err:    "memory: quantities must be positive",
+		},
+		{
+			name:   "negative cap",
+			policy: recommend.Policy{CPU: recommend.ResourcePolicy{MaxLimit: ptr.To(resource.MustParse("-2"))}},
+			err:    "cpu: quantities must be positive",
+		},
+		{
+			name:   "unknown target qos",
+			policy: recommend.Policy{TargetQoS: "besteffort"},
+			err:    `targetQoS: unknown target QoS "besteffort"`,
+		},
+		{
+			name: "invalid selector",
+			policy: recommend.Policy{Overrides: []recommend.PolicyOverride{
+				{Match: recommend.PolicyMatch{Selector: "tier in (db"}},
+			}},
+			err: "overrides[0]: invalid selector",
+		},
+		{
+			name: "invalid name pattern",
+			policy: recommend.Policy{Overrides: []recommend.PolicyOverride{
+				{Match: recommend.PolicyMatch{Name: "pg-["}},
+			}},
+			err: "overrides[0]: invalid name pattern",
+		},
+		{
+			name: "unknown strategy statistic",
+			policy: recommend.Policy{Strategies: map[string]recommend.Strategy{
+				"custom": {CPURequest: recommend.Estimator{Statistic: "median"}},
+			}},
+			err: `strategies.custom: unknown statistic "median"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+const (
+	// TargetQoSBurstable keeps limits above requests, this is the default sizing.
+	TargetQoSBurstable = "burstable"
+	// TargetQoSGuaranteed sets limits equal to requests.
+	TargetQoSGuaranteed = "guaranteed"
+	// TargetQoSGuaranteedInteger sets limits equal to requests and rounds CPU up to whole cores,
+	// so the static CPU manager policy can pin exclusive cores.
+	TargetQoSGuaranteedInteger = "guaranteed-integer"
+	// TargetQoSNoCPULimit removes CPU limits to avoid CFS throttling.
+	TargetQoSNoCPULimit = "no-cpu-limit"
+)
+
+// TargetQoSNames returns the names of the supported QoS targets.
+func TargetQoSNames() []string {
+	return []string{TargetQoSBurstable, TargetQoSGuaranteed, TargetQoSGuaranteedInteger, TargetQoSNoCPULimit}
+}
+
+func validateTargetQoS(name, target string) error {
+	switch target {
+	case "", TargetQoSBurstable, TargetQoSGuaranteed, TargetQoSGuaranteedInteger, TargetQoSNoCPULimit:
+		return nil
+	}
+
+	return fmt.Errorf("%s: unknown target QoS %q", name, target)
+}
+
+// targetQoSFor returns the QoS target of the container, overrides of the policy take precedence over the target option.
+func (p *Policy) targetQoSFor(r resources.ResourceInfo, target string) string {
+	if p == nil {
+		return target
+	}
+
+	if target == "" {
+		target = p.TargetQoS
+	}
+
+	for _, o := range p.Overrides {
+		if o.TargetQoS != "" && o.Match.matches(r) {
+			target = o.TargetQoS
+		}
+	}
+
+	return target
+}
+
+// applyTargetQoS adjusts the recommendation to reach the QoS target.
+func applyTargetQoS(rec *resources.ResourceRecommendation, target string) {
+	switch target {
+	case TargetQoSGuaranteedInteger:
+		if cpu := effective(rec.RecommendedCPURequest, rec.CurrentCPURequest); cpu%1000 != 0 {
+			rec.RecommendedCPURequest = (cpu/1000 + 1) * 1000
+			rec.Notes = append(rec.Notes, "CPU request rounded up to whole cores for the static CPU manager")
+		}
+
+		fallthrough
+	case TargetQoSGuaranteed:
+		cpu := guaranteedLimit(rec.RecommendedCPURequest, rec.CurrentCPURequest, rec.CurrentCPULimit, &rec.RecommendedCPULimit)
+		mem := guaranteedLimit(rec.RecommendedMemRequest, rec.CurrentMemRequest, rec.CurrentMemLimit, &rec.RecommendedMemLimit)
+
+		if cpu || mem {
+			rec.Notes = append(rec.Notes, "limits set to requests for the Guaranteed QoS class")
+		}
+	case TargetQoSNoCPULimit:
+		rec.RecommendedCPULimit = 0
+
+		if rec.CurrentCPULimit > 0 {
+			rec.RemoveCPULimit = true
+			rec.Notes = append(rec.Notes, "CPU limit removed to avoid throttling")
+		}
+	}
+}
+
+// guaranteedLimit sets the recommended limit to the request and reports whether the limit changes.
+func guaranteedLimit(recommendedRequest, currentRequest, currentLimit int64, recommendedLimit *int64) bool {
+	request := effective(recommendedRequest, currentRequest)
+	if request == 0 {
+		return false
+	}
+
+	if request == currentLimit {
+		*recommendedLimit = 0
+
+		return false
+	}
+
+	*recommendedLimit = request
+
+	return true
+}
+
+// effective returns the recommended value, or the current one when the recommendation keeps it.
+func effective(recommended, current int64) int64 {
+	if recommended > 0 {
+		return recommended
+	}
+
+	return current
+}
@@ -17,29 +17,77 @@ limitations under the License.
 package recommend
 
 import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/sergelogvinov/helm-resources/pkg/cluster"
 	"github.com/sergelogvinov/helm-resources/pkg/resources"
 )
 
+// Options configures the recommendation analysis.
+type Options struct {
+	// Policy defines headroom, rounding and caps of recommendations, nil uses the built-in defaults.
+	Policy *Policy
+	// Strategy defines which usage statistics size requests and limits, nil uses the default strategy.
+	Strategy *Strategy
+	// TargetQoS is the QoS target of containers without a policy target, see TargetQoSNames.
+	TargetQoS string
+	// Constraints are the LimitRange and ResourceQuota constraints of the namespace, nil means unconstrained.
+	Constraints *cluster.Constraints
+	// Horizontal enables the advice to scale stateless workloads out instead of up.
+	Horizontal bool
+	// Downsize enables recommendations to lower requests and limits of over-provisioned containers.
+	Downsize bool
+	// DownsizeThreshold is the usage to request ratio, in percent, below which a container is over-provisioned.
+	DownsizeThreshold int
+}
+
 // AnalyzeRecommendations analyzes the provided resource information and generates recommendations
 // for CPU and memory requests and limits based on observed usage.
-func AnalyzeRecommendations(res []resources.ResourceInfo) []resources.ResourceRecommendation {
+// The risk of the containers is taken from ScoreRisk, which must be called first.
+// Recommendations are sorted by risk score, the most urgent first.
+func AnalyzeRecommendations(res []resources.ResourceInfo, opts Options) []resources.ResourceRecommendation {
 	var recommendations []resources.ResourceRecommendation
 
+	strategy := opts.Strategy
+	if strategy == nil {
+		strategy, _ = (*Policy)(nil).Strategy(StrategyDefault) //nolint:errcheck
+	}
+
 	for _, r := range res {
-		if r.CPUUsage == 0 || r.MemUsage == 0 {
+		notes := eventNotes(r)
+
+		if r.Severity == SeverityCritical {
+			notes = append(notes, r.RiskReasons...)
+		}
+
+		target := opts.Policy.targetQoSFor(r, opts.TargetQoS)
+
+		hasUsage := r.CPUUsage > 0 && r.MemUsage > 0 ||
+			strategy.usesVPA() && r.VPA != nil && r.VPA.Target.CPU > 0 && r.VPA.Target.Mem > 0
+		if !hasUsage && len(notes) == 0 && target == "" {
 			continue
 		}
 
+		cpuUsage, memUsage, replicaNotes := replicaUsage(r)
+
+		if hasUsage {
+			notes = append(notes, missingRequestsNotes(r)...)
+			notes = append(notes, replicaNotes...)
+		}
+
 		rec := resources.ResourceRecommendation{
 			Chart:     r.Chart,
 			Release:   r.Release,
 			Kind:      r.Kind,
 			Name:      r.Name,
 			Container: r.Container,
+			Notes:     notes,
+			RiskScore: r.RiskScore,
+			Severity:  r.Severity,
 		}
 
-		needsUpdate := false
-
 		rec.CPUUsage = r.CPUUsage
 		rec.MemUsage = r.MemUsage
 		rec.CurrentCPURequest = r.CPURequest
@@ -47,34 +95,111 @@ func AnalyzeRecommendations(res []resources.ResourceInfo) []resources.ResourceRe
 		rec.CurrentCPULimit = r.CPULimit
 		rec.CurrentMemLimit = r.MemLimit
 
-		if r.CPUUsage > 0 && r.CPURequest > 0 && r.CPUUsage > r.CPURequest {
-			recommendedCPU := roundUpCPULow(r.CPUUsage)
-			rec.RecommendedCPURequest = recommendedCPU
+		cpu, mem := opts.Policy.sizingFor(r)
 
-			recommendedCPULimit := roundUpCPUHigh(r.CPUUsage)
-			if recommendedCPULimit >= rec.CurrentCPULimit {
-				rec.RecommendedCPULimit = recommendedCPULimit
-			}
+		if hasUsage {
+			cpuStats := withVPA(r.CPUStats, r.VPA.CPUStatistics())
+			memStats := withVPA(r.MemStats, r.VPA.MemStatistics())
 
-			needsUpdate = true
+			cpuEstimate, cpuSizing := estimateResource(cpu, strategy.CPURequest, strategy.CPULimit, cpuUsage, cpuStats)
+			memEstimate, memSizing := estimateResource(mem, strategy.MemRequest, strategy.MemLimit, memUsage, memStats)
+
+			rec.RecommendedCPURequest, rec.RecommendedCPULimit = recommendResource(cpuSizing, cpuEstimate, r.CPURequest, r.CPULimit, opts)
+			rec.RecommendedMemRequest, rec.RecommendedMemLimit = recommendResource(memSizing, memEstimate, r.MemRequest, r.MemLimit, opts)
 		}
 
-		if r.MemUsage > 0 && r.MemRequest > 0 && r.MemUsage > r.MemRequest {
-			recommendedMem := roundUpMemoryLow(r.MemUsage)
-			rec.RecommendedMemRequest = recommendedMem
+		applyTargetQoS(&rec, target)
+		applyLimitRange(&rec, opts.Constraints)
+		// The hard caps come last, so whole-core rounding and LimitRange minimums are escalated as well.
+		capRecommendation(&rec, cpu, mem)
+
+		if rec.HasChanges() || len(rec.Notes) > 0 {
+			replicas := replicaCount(r.Replicas)
 
-			recommendedMemLimit := roundUpMemoryHigh(r.MemUsage)
-			if recommendedMemLimit >= rec.CurrentMemLimit {
-				rec.RecommendedMemLimit = recommendedMemLimit
+			if rec.RecommendedCPURequest > 0 && rec.RecommendedCPURequest < rec.CurrentCPURequest {
+				rec.ReclaimableCPU = (rec.CurrentCPURequest - rec.RecommendedCPURequest) * replicas
 			}
 
-			needsUpdate = true
-		}
+			if rec.RecommendedMemRequest > 0 && rec.RecommendedMemRequest < rec.CurrentMemRequest {
+				rec.ReclaimableMem = (rec.CurrentMemRequest - rec.RecommendedMemRequest) * replicas
+			}
 
-		if needsUpdate {
 			recommendations = append(recommendations, rec)
 		}
 	}
 
+	if opts.Horizontal {
+		adviseScaling(res, recommendations, opts)
+	}
+
+	recommendAutoscalerTargets(res, recommendations)
+
+	slices.SortStableFunc(recommendations, func(a, b resources.ResourceRecommendation) int {
+		return cmp.Compare(b.RiskScore, a.RiskScore)
+	})
+
 	return recommendations
 }
+
+// recommendResource returns the recommended request and limit of a resource based on the strategy estimate.
+// A zero value means the current value should be kept, as it is without an estimate of the request.
+func recommendResource(s sizing, est estimate, request, limit int64, opts Options) (int64, int64) {
+	var recommendedRequest, recommendedLimit int64
+
+	if !est.hasRequest {
+		return 0, 0
+	}
+
+	switch {
+	case request == 0:
+		recommendedRequest = s.request(est.request)
+
+		if l := s.limit(est.limit); est.hasLimit && l > limit {
+			recommendedLimit = l
+		}
+	case est.request > request:
+		recommendedRequest = s.request(est.request)
+
+		if l := s.limit(est.limit); est.hasLimit && l >= limit {
+			recommendedLimit = l
+		}
+	case opts.Downsize && overProvisioned(est.request, request, opts.DownsizeThreshold):
+		if est.hasLimit {
+			recommendedRequest, recommendedLimit = downsize(request, s.request(est.request), limit, s.limit(est.limit))
+		} else {
+			recommendedRequest, _ = downsize(request, s.request(est.request), 0, 0)
+		}
+	}
+
+	// The request must not exceed the current limit.
+	if limit > 0 && recommendedLimit == 0 && recommendedRequest > limit {
+		recommendedLimit = recommendedRequest
+	}
+
+	return recommendedRequest, recommendedLimit
+}
+
+// capRecommendation limits the recommendation to the policy hard caps and flags an escalation when capped.
+func capRecommendation(rec *resources.ResourceRecommendation, cpu, mem sizing) {
+	for _, c := range []struct {
+		name   string
+		value  *int64
+		limit  int64
+		format func(int64) string
+	}{
+		{"CPU request", &rec.RecommendedCPURequest, cpu.maxRequest, formatMilliCores},
+		{"CPU limit", &rec.RecommendedCPULimit, cpu.maxLimit, formatMilliCores},
+		{"memory request", &rec.RecommendedMemRequest, mem.maxRequest, resources.FormatMemory},
+		{"memory limit", &rec.RecommendedMemLimit, mem.maxLimit, resources.FormatMemory},
+	} {
+		if c.limit <= 0 || *c.value <= c.limit {
+			continue
+		}
+
+		rec.Escalation = true
+		rec.Notes = append(rec.Notes, fmt.Sprintf("escalation: %s %s exceeds the policy cap %s",
+			c.name, c.format(*c.value), c.format(c.limit)))
+
+		*c.value = c.limit
+	}
+}
@@ -0,0 +1,569 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergelogvinov/helm-resources/pkg/cluster"
+	"github.com/sergelogvinov/helm-resources/pkg/recommend"
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+const mi = 1024 * 1024
+
+func TestAnalyzeRecommendations(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   recommend.Options
+		res    []resources.ResourceInfo
+		expect []resources.ResourceRecommendation
+	}{
+		{
+			name: "usage below requests",
+			res: []resources.ResourceInfo{
+				{Kind: "Deployment", Name: "web", Container: "web", CPURequest: 500, MemRequest: 512 * mi, CPUUsage: 100, MemUsage: 128 * mi},
+			},
+		},
+		{
+			name: "usage above requests",
+			res: []resources.ResourceInfo{
+				{Kind: "Deployment", Name: "web", Container: "web", CPURequest: 100, MemRequest: 128 * mi, CPUUsage: 150, MemUsage: 200 * mi},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "Deployment", Name: "web", Container: "web",
+					CPUUsage: 150, MemUsage: 200 * mi,
+					CurrentCPURequest: 100, RecommendedCPURequest: 200, RecommendedCPULimit: 300,
+					CurrentMemRequest: 128 * mi, RecommendedMemRequest: 256 * mi, RecommendedMemLimit: 512 * mi,
+					RiskScore: 15, Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "sorted by risk",
+			res: []resources.ResourceInfo{
+				{Kind: "Deployment", Name: "web", Container: "web", CPURequest: 100, MemRequest: 128 * mi, CPUUsage: 150, MemUsage: 100 * mi},
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db", Replicas: "1",
+					CPURequest: 100, MemRequest: 128 * mi, MemLimit: 256 * mi, CPUUsage: 150, MemUsage: 250 * mi, OOMKills: 2,
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db",
+					CPUUsage: 150, MemUsage: 250 * mi,
+					CurrentCPURequest: 100, RecommendedCPURequest: 200, RecommendedCPULimit: 300,
+					CurrentMemRequest: 128 * mi, RecommendedMemRequest: 384 * mi, CurrentMemLimit: 256 * mi, RecommendedMemLimit: 512 * mi,
+					Notes:     []string{"memory usage at 98% of limit", "2 OOM kill(s)"},
+					RiskScore: 95, Severity: recommend.SeverityCritical,
+				},
+				{
+					Kind: "Deployment", Name: "web", Container: "web",
+					CPUUsage: 150, MemUsage: 100 * mi,
+					CurrentCPURequest: 100, RecommendedCPURequest: 200, RecommendedCPULimit: 300,
+					CurrentMemRequest: 128 * mi,
+					RiskScore:         5, Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "over-provisioned without downsize",
+			res: []resources.ResourceInfo{
+				{Kind: "Deployment", Name: "web", Container: "web", CPURequest: 1000, MemRequest: 4096 * mi, CPUUsage: 100, MemUsage: 300 * mi},
+			},
+		},
+		{
+			name: "over-provisioned downsize",
+			opts: recommend.Options{Downsize: true, DownsizeThreshold: 50},
+			res: []resources.ResourceInfo{
+				{
+					Kind: "Deployment", Name: "web", Container: "web", Replicas: "3",
+					CPURequest: 1000, MemRequest: 4096 * mi, CPULimit: 2000, MemLimit: 4096 * mi, CPUUsage: 100, MemUsage: 300 * mi,
+				},
+				{Kind: "Deployment", Name: "api", Container: "api", CPURequest: 200, MemRequest: 512 * mi, CPUUsage: 150, MemUsage: 400 * mi},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "Deployment", Name: "web", Container: "web",
+					CPUUsage: 100, MemUsage: 300 * mi,
+					CurrentCPURequest: 1000, RecommendedCPURequest: 200, CurrentCPULimit: 2000, RecommendedCPULimit: 200,
+					CurrentMemRequest: 4096 * mi, RecommendedMemRequest: 384 * mi, CurrentMemLimit: 4096 * mi, RecommendedMemLimit: 640 * mi,
+					RiskScore: 0, Severity: recommend.SeverityInfo,
+					ReclaimableCPU: 2400, ReclaimableMem: 3 * 3712 * mi,
+				},
+			},
+		},
+		{
+			name: "best effort container",
+			res: []resources.ResourceInfo{
+				{Kind: "Deployment", Name: "web", Container: "web", CPUUsage: 150, MemUsage: 200 * mi},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "Deployment", Name: "web", Container: "web",
+					CPUUsage: 150, MemUsage: 200 * mi,
+					RecommendedCPURequest: 200, RecommendedCPULimit: 300,
+					RecommendedMemRequest: 256 * mi, RecommendedMemLimit: 512 * mi,
+					Notes:    []string{"no requests and limits set, the container runs as BestEffort"},
+					Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "policy overrides and caps",
+			opts: recommend.Options{Policy: &recommend.Policy{
+				CPU: recommend.ResourcePolicy{MaxRequest: ptr.To(resource.MustParse("2"))},
+				Overrides: []recommend.PolicyOverride{
+					{
+						Match:  recommend.PolicyMatch{Labels: map[string]string{"severity": "critical"}},
+						Memory: recommend.ResourcePolicy{RequestMultiplier: 2, Step: ptr.To(resource.MustParse("64Mi"))},
+					},
+				},
+			}},
+			res: []resources.ResourceInfo{
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db", WorkloadLabels: map[string]string{"severity": "critical"},
+					CPURequest: 1000, MemRequest: 128 * mi, CPUUsage: 3000, MemUsage: 200 * mi,
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db",
+					CPUUsage: 3000, MemUsage: 200 * mi,
+					CurrentCPURequest: 1000, RecommendedCPURequest: 2000, RecommendedCPULimit: 6000,
+					CurrentMemRequest: 128 * mi, RecommendedMemRequest: 448 * mi, RecommendedMemLimit: 448 * mi,
+					Notes:      []string{"escalation: CPU request 4000m exceeds the policy cap 2000m"},
+					Escalation: true,
+					RiskScore:  15, Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "cost-optimized strategy",
+			opts: recommend.Options{Strategy: ptr.To(recommend.BuiltinStrategies()[recommend.StrategyCostOptimized])},
+			res: []resources.ResourceInfo{
+				{
+					Kind: "Deployment", Name: "web", Container: "web", CPURequest: 100, MemRequest: 128 * mi, CPUUsage: 150, MemUsage: 180 * mi,
+					CPUStats: map[string]int64{"p50": 120},
+					MemStats: map[string]int64{"p95": 200 * mi, "p99": 220 * mi},
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "Deployment", Name: "web", Container: "web",
+					CPUUsage: 150, MemUsage: 180 * mi,
+					CurrentCPURequest: 100, RecommendedCPURequest: 200,
+					CurrentMemRequest: 128 * mi, RecommendedMemRequest: 256 * mi, RecommendedMemLimit: 256 * mi,
+					RiskScore: 15, Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "guaranteed integer target",
+			opts: recommend.Options{TargetQoS: recommend.TargetQoSGuaranteedInteger},
+			res: []resources.ResourceInfo{
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db",
+					CPURequest: 1500, MemRequest: 4096 * mi, CPULimit: 2000, MemLimit: 8192 * mi, CPUUsage: 1000, MemUsage: 2048 * mi,
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db",
+					CPUUsage: 1000, MemUsage: 2048 * mi,
+					CurrentCPURequest: 1500, RecommendedCPURequest: 2000, CurrentCPULimit: 2000,
+					CurrentMemRequest: 4096 * mi, CurrentMemLimit: 8192 * mi, RecommendedMemLimit: 4096 * mi,
+					Notes: []string{
+						"CPU request rounded up to whole cores for the static CPU manager",
+						"limits set to requests for the Guaranteed QoS class",
+					},
+					Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "caps after the qos target and limit range",
+			opts: recommend.Options{
+				TargetQoS: recommend.TargetQoSGuaranteedInteger,
+				Policy: &recommend.Policy{
+					CPU:    recommend.ResourcePolicy{MaxRequest: ptr.To(resource.MustParse("1500m"))},
+					Memory: recommend.ResourcePolicy{MaxRequest: ptr.To(resource.MustParse("768Mi"))},
+				},
+				Constraints: &cluster.Constraints{
+					Limits: cluster.ContainerLimits{Memory: cluster.ResourceLimits{Min: 1024 * mi}},
+				},
+			},
+			res: []resources.ResourceInfo{
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db",
+					CPURequest: 1200, MemRequest: 512 * mi, CPULimit: 1200, MemLimit: 1024 * mi, CPUUsage: 1000, MemUsage: 256 * mi,
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db",
+					CPUUsage: 1000, MemUsage: 256 * mi,
+					CurrentCPURequest: 1200, RecommendedCPURequest: 1500, CurrentCPULimit: 1200, RecommendedCPULimit: 2000,
+					CurrentMemRequest: 512 * mi, RecommendedMemRequest: 768 * mi, CurrentMemLimit: 1024 * mi,
+					Notes: []string{
+						"CPU request rounded up to whole cores for the static CPU manager",
+						"limits set to requests for the Guaranteed QoS class",
+						"memory request 512Mi raised to the LimitRange minimum 1.0Gi",
+						"memory limit 512Mi raised to the LimitRange minimum 1.0Gi",
+						"escalation: CPU request 2000m exceeds the policy cap 1500m",
+						"escalation: memory request 1.0Gi exceeds the policy cap 768Mi",
+					},
+					Escalation: true,
+					Severity:   recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "no cpu limit policy target",
+			opts: recommend.Options{
+				TargetQoS: recommend.TargetQoSGuaranteed,
+				Policy: &recommend.Policy{
+					Overrides: []recommend.PolicyOverride{
+						{Match: recommend.PolicyMatch{Kind: "Deployment"}, TargetQoS: recommend.TargetQoSNoCPULimit},
+					},
+				},
+			},
+			res: []resources.ResourceInfo{
+				{
+					Kind: "Deployment", Name: "web", Container: "web",
+					CPURequest: 500, MemRequest: 512 * mi, CPULimit: 1000, MemLimit: 512 * mi, CPUUsage: 100, MemUsage: 128 * mi,
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "Deployment", Name: "web", Container: "web",
+					CPUUsage: 100, MemUsage: 128 * mi,
+					CurrentCPURequest: 500, CurrentCPULimit: 1000, RemoveCPULimit: true,
+					CurrentMemRequest: 512 * mi, CurrentMemLimit: 512 * mi,
+					Notes:    []string{"CPU limit removed to avoid throttling"},
+					Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "limit range",
+			opts: recommend.Options{Constraints: &cluster.Constraints{
+				Limits: cluster.ContainerLimits{
+					CPU:    cluster.ResourceLimits{Max: 250},
+					Memory: cluster.ResourceLimits{MaxLimitRequestRatio: 1.5},
+				},
+			}},
+			res: []resources.ResourceInfo{
+				{
+					Kind: "Deployment", Name: "web", Container: "web",
+					CPURequest: 100, MemRequest: 128 * mi, CPULimit: 200, MemLimit: 256 * mi, CPUUsage: 150, MemUsage: 100 * mi,
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "Deployment", Name: "web", Container: "web",
+					CPUUsage: 150, MemUsage: 100 * mi,
+					CurrentCPURequest: 100, RecommendedCPURequest: 200, CurrentCPULimit: 200, RecommendedCPULimit: 250,
+					CurrentMemRequest: 128 * mi, CurrentMemLimit: 256 * mi, RecommendedMemLimit: 192 * mi,
+					Notes: []string{
+						"CPU limit 300m lowered to the LimitRange maximum 250m",
+						"memory limit lowered to 192Mi by the LimitRange limit to request ratio 1.5",
+					},
+					RiskScore: 5, Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "autoscaler target",
+			res: []resources.ResourceInfo{
+				{
+					Kind: "Deployment", Name: "web", Container: "web", CPURequest: 100, MemRequest: 256 * mi, CPUUsage: 150, MemUsage: 100 * mi,
+					Autoscaler: &resources.Autoscaler{Kind: resources.AutoscalerHPA, Name: "web", MinReplicas: 2, MaxReplicas: 10, CPUUtilization: 80},
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "Deployment", Name: "web", Container: "web",
+					CPUUsage: 150, MemUsage: 100 * mi,
+					CurrentCPURequest: 100, RecommendedCPURequest: 200, RecommendedCPULimit: 300,
+					CurrentMemRequest:     256 * mi,
+					CurrentCPUUtilization: 80, RecommendedCPUUtilization: 40,
+					Notes:     []string{"HorizontalPodAutoscaler web CPU target 80% -> 40% keeps the scaling threshold"},
+					RiskScore: 5, Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "horizontal scaling",
+			opts: recommend.Options{Horizontal: true},
+			res: []resources.ResourceInfo{
+				{
+					Kind: "Deployment", Name: "api", Container: "api", Replicas: "2",
+					CPURequest: 1000, MemRequest: 512 * mi, CPUUsage: 2000, MemUsage: 256 * mi,
+					Pods: []resources.PodUsage{{Pod: "api-7d9f8b6c5d-abcde", CPU: 1800}, {Pod: "api-7d9f8b6c5d-fghij", CPU: 2200}},
+				},
+				{
+					Kind: "Deployment", Name: "worker", Container: "worker", Replicas: "2",
+					CPURequest: 1000, MemRequest: 512 * mi, CPUUsage: 2000, MemUsage: 256 * mi,
+					Pods: []resources.PodUsage{{Pod: "worker-7d9f8b6c5d-abcde", CPU: 500}, {Pod: "worker-7d9f8b6c5d-fghij", CPU: 3500}},
+				},
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db", Replicas: "3",
+					CPURequest: 1000, MemRequest: 512 * mi, CPUUsage: 2000, MemUsage: 256 * mi,
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "Deployment", Name: "api", Container: "api",
+					CPUUsage: 2000, MemUsage: 256 * mi,
+					CurrentCPURequest: 1000, RecommendedCPULimit: 2000,
+					CurrentMemRequest: 512 * mi, RecommendedReplicas: 5,
+					Notes:     []string{"scale out to 5 replicas at 1000m CPU instead of 2500m per pod"},
+					RiskScore: 5, Severity: recommend.SeverityInfo,
+				},
+				{
+					Kind: "Deployment", Name: "worker", Container: "worker",
+					CPUUsage: 2000, MemUsage: 256 * mi,
+					CurrentCPURequest: 1000, RecommendedCPURequest: 2500, RecommendedCPULimit: 4000,
+					CurrentMemRequest: 512 * mi,
+					Notes:             []string{"scale up, CPU usage is uneven across pods (stddev 75% of average)"},
+					RiskScore:         5, Severity: recommend.SeverityInfo,
+				},
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db",
+					CPUUsage: 2000, MemUsage: 256 * mi,
+					CurrentCPURequest: 1000, RecommendedCPURequest: 2500, RecommendedCPULimit: 4000,
+					CurrentMemRequest: 512 * mi,
+					Notes:             []string{"scale up, StatefulSet replicas are not interchangeable"},
+					RiskScore:         5, Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "statefulset sized for the busiest pod",
+			res: []resources.ResourceInfo{
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db", Replicas: "3",
+					CPURequest: 500, MemRequest: 512 * mi, CPUUsage: 200, MemUsage: 256 * mi,
+					Pods: []resources.PodUsage{
+						{Pod: "db-0", CPU: 800, Mem: 300 * mi, Outlier: true},
+						{Pod: "db-1", CPU: 100, Mem: 250 * mi},
+						{Pod: "db-2", CPU: 100, Mem: 220 * mi},
+					},
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db",
+					CPUUsage: 200, MemUsage: 256 * mi,
+					CurrentCPURequest: 500, RecommendedCPURequest: 1000, RecommendedCPULimit: 1600,
+					CurrentMemRequest: 512 * mi,
+					Notes: []string{
+						"outlier pod(s) db-0 use more than 2x the median usage",
+						"CPU sized for the busiest pod db-0",
+						"memory sized for the busiest pod db-0",
+					},
+					Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "vpa strategy",
+			opts: recommend.Options{Strategy: ptr.To(recommend.BuiltinStrategies()[recommend.StrategyVPA])},
+			res: []resources.ResourceInfo{
+				{
+					Kind: "Deployment", Name: "web", Container: "web", CPURequest: 100, MemRequest: 128 * mi,
+					VPA: &resources.VPARecommendation{
+						Name:       "goldilocks-web",
+						LowerBound: resources.ResourceValues{CPU: 50, Mem: 100 * mi},
+						Target:     resources.ResourceValues{CPU: 230, Mem: 260 * mi},
+						UpperBound: resources.ResourceValues{CPU: 900, Mem: 700 * mi},
+					},
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "Deployment", Name: "web", Container: "web",
+					CurrentCPURequest: 100, RecommendedCPURequest: 300,
+					CurrentMemRequest: 128 * mi, RecommendedMemRequest: 384 * mi, RecommendedMemLimit: 768 * mi,
+					Severity: recommend.SeverityInfo,
+				},
+			},
+		},
+		{
+			name: "vpa strategy without a vpa",
+			opts: recommend.Options{Strategy: ptr.To(recommend.BuiltinStrategies()[recommend.StrategyVPA])},
+			res: []resources.ResourceInfo{
+				{Kind: "Deployment", Name: "web", Container: "web", CPURequest: 100, MemRequest: 128 * mi, CPUUsage: 150, MemUsage: 200 * mi},
+			},
+		},
+		{
+			name: "pending pods without usage",
+			res: []resources.ResourceInfo{
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db", CPURequest: 100, MemRequest: 24 * 1024 * mi,
+					Events: []resources.PodEvent{
+						{Pod: "db-0", Reason: resources.EventFailedScheduling, Resources: []string{"memory"}},
+					},
+				},
+			},
+			expect: []resources.ResourceRecommendation{
+				{
+					Kind: "StatefulSet", Name: "db", Container: "db",
+					CurrentCPURequest: 100, CurrentMemRequest: 24 * 1024 * mi,
+					Notes:     []string{"1 pod(s) pending, requests do not fit on any node: insufficient memory"},
+					RiskScore: 20, Severity: recommend.SeverityWarning,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recommend.ScoreRisk(tt.res)
+
+			recs := recommend.AnalyzeRecommendations(tt.res, tt.opts)
+
+			assert.Equal(t, tt.expect, recs)
+		})
+	}
+}
+
+func TestCheckQuotas(t *testing.T) {
+	res := []resources.ResourceInfo{
+		{Kind: "Deployment", Name: "web", Container: "web", Replicas: "3"},
+		{Kind: "Deployment", Name: "api", Container: "api", Replicas: "2"},
+	}
+	recs := []resources.ResourceRecommendation{
+		{
+			Kind: "Deployment", Name: "web", Container: "web",
+			CurrentCPURequest: 100, RecommendedCPURequest: 200, CurrentCPULimit: 500, RemoveCPULimit: true,
+		},
+		{
+			Kind: "Deployment", Name: "api", Container: "api",
+			CurrentMemRequest: 512 * mi, RecommendedMemRequest: 256 * mi,
+		},
+	}
+	quotas := []cluster.Quota{
+		{
+			Name: "compute",
+			Hard: map[string]int64{cluster.QuotaRequestsCPU: 1000, cluster.QuotaRequestsMemory: 4096 * mi, cluster.QuotaLimitsCPU: 4000},
+			Used: map[string]int64{cluster.QuotaRequestsCPU: 800, cluster.QuotaRequestsMemory: 2048 * mi, cluster.QuotaLimitsCPU: 3000},
+		},
+	}
+
+	headroom := recommend.CheckQuotas(res, recs, quotas)
+
+	assert.Equal(t, []recommend.QuotaHeadroom{
+		{Quota: "compute", Resource: cluster.QuotaRequestsCPU, Hard: 1000, Used: 800, Change: 300},
+		{Quota: "compute", Resource: cluster.QuotaRequestsMemory, Hard: 4096 * mi, Used: 2048 * mi, Change: -512 * mi},
+		{Quota: "compute", Resource: cluster.QuotaLimitsCPU, Hard: 4000, Used: 3000, Change: -1500},
+	}, headroom)
+	assert.Equal(t, int64(-100), headroom[0].Headroom())
+	assert.Equal(t, []string{"exceeds the ResourceQuota compute requests.cpu"}, recs[0].Notes)
+	assert.Empty(t, recs[1].Notes)
+}
+
+func TestCheckNodeFit(t *testing.T) {
+	const gi = 1024 * mi
+
+	res := []resources.ResourceInfo{
+		{
+			Kind: "StatefulSet", Name: "db", Container: "db", Replicas: "2", MemRequest: 8 * gi,
+			Scheduling: &resources.SchedulingConstraints{NodeSelector: map[string]string{"pool": "db"}},
+		},
+		{
+			Kind: "Deployment", Name: "big", Container: "big", Replicas: "1", CPURequest: 500, MemRequest: 8 * gi,
+			Scheduling: &resources.SchedulingConstraints{},
+		},
+		{
+			Kind: "Deployment", Name: "gpu", Container: "gpu", Replicas: "1", MemRequest: 1 * gi,
+			Scheduling: &resources.SchedulingConstraints{NodeSelector: map[string]string{"pool": "gpu"}},
+		},
+		{
+			Kind: "Deployment", Name: "small", Container: "small", Replicas: "1", MemRequest: 2 * gi,
+			Scheduling: &resources.SchedulingConstraints{NodeSelector: map[string]string{"pool": "gpu"}},
+		},
+	}
+	recs := []resources.ResourceRecommendation{
+		{Kind: "StatefulSet", Name: "db", Container: "db", CurrentMemRequest: 8 * gi, RecommendedMemRequest: 24 * gi},
+		{Kind: "Deployment", Name: "big", Container: "big", CurrentCPURequest: 500, CurrentMemRequest: 8 * gi, RecommendedMemRequest: 128 * gi},
+		{Kind: "Deployment", Name: "gpu", Container: "gpu", CurrentMemRequest: 1 * gi, RecommendedMemRequest: 2 * gi},
+		{Kind: "Deployment", Name: "small", Container: "small", CurrentMemRequest: 2 * gi, RecommendedMemRequest: 1 * gi},
+	}
+	nodes := []cluster.Node{
+		{
+			Name: "n1", Labels: map[string]string{"pool": "db"}, AllocatableCPU: 4000, AllocatableMem: 32 * gi, RequestedMem: 8 * gi,
+			Pods: []cluster.Pod{{Namespace: "default", Name: "db-0", Mem: 8 * gi}},
+		},
+		{
+			Name: "n2", Labels: map[string]string{"pool": "db"}, AllocatableCPU: 4000, AllocatableMem: 32 * gi, RequestedMem: 20 * gi,
+			Pods: []cluster.Pod{{Namespace: "default", Name: "db-1", Mem: 8 * gi}, {Namespace: "other", Name: "cache-0", Mem: 12 * gi}},
+		},
+		{
+			Name: "n3", Labels: map[string]string{"pool": "web"}, AllocatableCPU: 4000, AllocatableMem: 64 * gi,
+			Taints: []v1.Taint{{Key: "dedicated", Value: "web", Effect: v1.TaintEffectNoSchedule}},
+		},
+	}
+
+	recommend.CheckNodeFit("default", res, recs, nodes)
+
+	assert.Equal(t, []string{"1 of 2 pod(s) do not fit on the current nodes by requests, a new node is required"}, recs[0].Notes)
+	assert.Equal(t, []string{"pod requests 500m/128.0Gi exceed the allocatable of every matching node, pods would stay Pending"}, recs[1].Notes)
+	assert.Equal(t, []string{"no node matches the node selector, affinity and tolerations, pods would stay Pending"}, recs[2].Notes)
+	assert.Empty(t, recs[3].Notes)
+}
+
+func TestOvercommit(t *testing.T) {
+	const gi = 1024 * mi
+
+	res := []resources.ResourceInfo{
+		{Kind: "StatefulSet", Name: "db", Container: "metrics", MemRequest: 64 * mi, MemLimit: 128 * mi},
+		{Kind: "StatefulSet", Name: "db", Container: "db", CPURequest: 1000, CPULimit: 2000, MemRequest: 4 * gi, MemLimit: 12 * gi},
+		{Kind: "Deployment", Name: "web", Container: "web", MemRequest: 1 * gi, MemLimit: 1 * gi},
+	}
+	nodes := []cluster.Node{
+		{
+			Name: "n1", AllocatableCPU: 4000, AllocatableMem: 16 * gi, RequestedCPU: 3000, RequestedMem: 12 * gi, LimitedCPU: 8000, LimitedMem: 24 * gi,
+			Pods: []cluster.Pod{{Namespace: "default", Name: "db-0"}, {Namespace: "default", Name: "web-5d8f7-x2x4z"}, {Namespace: "other", Name: "db-0"}},
+		},
+		{
+			Name: "n2", AllocatableCPU: 4000, AllocatableMem: 16 * gi, LimitedMem: 32 * gi,
+			Pods: []cluster.Pod{{Namespace: "other", Name: "cache-0"}},
+		},
+	}
+
+	report := recommend.Overcommit("default", res, nodes)
+
+	assert.Equal(t, []recommend.NodeOvercommit{
+		{
+			Node: "n1", AllocatableCPU: 4000, AllocatableMem: 16 * gi, RequestedCPU: 3000, RequestedMem: 12 * gi, LimitedCPU: 8000, LimitedMem: 24 * gi,
+			Contributors: []recommend.OvercommitContributor{
+				{Kind: "StatefulSet", Name: "db", Container: "db", Pods: 1, CPU: 1000, Mem: 8 * gi},
+				{Kind: "StatefulSet", Name: "db", Container: "metrics", Pods: 1, Mem: 64 * mi},
+			},
+		},
+	}, report)
+	assert.InDelta(t, 1.5, report[0].MemRatio(), 0.001)
+	assert.InDelta(t, 2.0, report[0].CPURatio(), 0.001)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+// replicaUsage returns the CPU and memory usage the container is sized for.
+// StatefulSets and DaemonSets are sized for their busiest pod, other workloads for the aggregated usage.
+func replicaUsage(r resources.ResourceInfo) (int64, int64, []string) {
+	cpu, mem := r.CPUUsage, r.MemUsage
+
+	var notes []string
+
+	if outliers := resources.Outliers(r.Pods); len(outliers) > 0 {
+		notes = append(notes, fmt.Sprintf("outlier pod(s) %s use more than %gx the median usage",
+			strings.Join(outliers, ", "), resources.OutlierFactor))
+	}
+
+	if !resources.SizedForBusiestPod(r.Kind) || len(r.Pods) < 2 {
+		return cpu, mem, notes
+	}
+
+	busiestCPU, busiestMem := resources.BusiestPod(r.Pods)
+
+	if busiestCPU.CPU > cpu {
+		cpu = busiestCPU.CPU
+		notes = append(notes, fmt.Sprintf("CPU sized for the busiest pod %s", busiestCPU.Pod))
+	}
+
+	if busiestMem.Mem > mem {
+		mem = busiestMem.Mem
+		notes = append(notes, fmt.Sprintf("memory sized for the busiest pod %s", busiestMem.Pod))
+	}
+
+	return cpu, mem, notes
+}
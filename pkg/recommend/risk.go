@@ -0,0 +1,150 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+const (
+	// SeverityCritical marks containers which are likely to fail now: OOM kills, pending pods, usage at the limit.
+	SeverityCritical = "critical"
+	// SeverityWarning marks containers which run above requests, are throttled or restart.
+	SeverityWarning = "warn"
+	// SeverityInfo marks containers without significant risk.
+	SeverityInfo = "info"
+
+	// severityCriticalScore is the minimum risk score of critical containers.
+	severityCriticalScore = 50
+	// severityWarningScore is the minimum risk score of containers worth a warning.
+	severityWarningScore = 20
+	// maxRiskScore is the maximum risk score.
+	maxRiskScore = 100
+)
+
+// ScoreRisk computes the risk score, severity and reasons of every container in place.
+func ScoreRisk(res []resources.ResourceInfo) {
+	for i := range res {
+		score, reasons := riskScore(res[i])
+
+		res[i].RiskScore = score
+		res[i].Severity = severity(score)
+		res[i].RiskReasons = reasons
+	}
+}
+
+// riskScore estimates how urgent it is to fix the container resources, from 0 to 100,
+// and returns the signals which contributed to the score.
+//
+//nolint:gocyclo,cyclop
+func riskScore(r resources.ResourceInfo) (int, []string) {
+	var (
+		score   int
+		reasons []string
+	)
+
+	if r.MemLimit > 0 && r.MemUsage > 0 {
+		ratio := float64(r.MemUsage) / float64(r.MemLimit)
+
+		switch {
+		case ratio >= 0.95:
+			score += 40
+		case ratio >= 0.85:
+			score += 25
+		case ratio >= 0.7:
+			score += 10
+		}
+
+		if ratio >= 0.85 {
+			reasons = append(reasons, fmt.Sprintf("memory usage at %.0f%% of limit", ratio*100))
+		}
+	}
+
+	if r.OOMKills > 0 {
+		score += 30
+
+		reasons = append(reasons, fmt.Sprintf("%d OOM kill(s)", r.OOMKills))
+	}
+
+	if r.CPULimit > 0 && r.CPUUsage > 0 {
+		ratio := float64(r.CPUUsage) / float64(r.CPULimit)
+
+		switch {
+		case ratio >= 1:
+			score += 20
+
+			reasons = append(reasons, fmt.Sprintf("CPU usage at %.0f%% of limit", ratio*100))
+		case ratio >= 0.9:
+			score += 10
+		}
+	}
+
+	switch {
+	case r.CPUThrottling >= 0.25:
+		score += 20
+
+		reasons = append(reasons, fmt.Sprintf("CPU throttled %.0f%% of periods", r.CPUThrottling*100))
+	case r.CPUThrottling >= 0.05:
+		score += 10
+	}
+
+	if r.MemRequest > 0 && r.MemUsage > r.MemRequest {
+		score += 10
+	}
+
+	if r.CPURequest > 0 && r.CPUUsage > r.CPURequest {
+		score += 5
+	}
+
+	switch {
+	case r.Restarts >= 5:
+		score += 15
+
+		reasons = append(reasons, fmt.Sprintf("%d restarts", r.Restarts))
+	case r.Restarts > 0:
+		score += 5
+	}
+
+	for _, e := range r.Events {
+		if e.Reason == resources.EventFailedScheduling || e.NodePressure {
+			score += 20
+
+			break
+		}
+	}
+
+	// A single replica has no redundancy, any failure is an outage.
+	if replicas, err := strconv.Atoi(r.Replicas); err == nil && replicas == 1 && score > 0 {
+		score += 10
+	}
+
+	return min(score, maxRiskScore), reasons
+}
+
+func severity(score int) string {
+	switch {
+	case score >= severityCriticalScore:
+		return SeverityCritical
+	case score >= severityWarningScore:
+		return SeverityWarning
+	}
+
+	return SeverityInfo
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergelogvinov/helm-resources/pkg/recommend"
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+func TestScoreRisk(t *testing.T) {
+	pending := resources.PodEvent{Pod: "web-0", Reason: resources.EventFailedScheduling, Resources: []string{"memory"}}
+
+	tests := []struct {
+		name     string
+		res      resources.ResourceInfo
+		score    int
+		severity string
+		reasons  []string
+	}{
+		{
+			name:     "no signals",
+			res:      resources.ResourceInfo{Replicas: "1", MemLimit: 512 * mi, MemUsage: 128 * mi, CPUUsage: 100, CPURequest: 200},
+			severity: recommend.SeverityInfo,
+		},
+		{
+			name:     "oom kills",
+			res:      resources.ResourceInfo{Replicas: "3", OOMKills: 2},
+			score:    30,
+			severity: recommend.SeverityWarning,
+			reasons:  []string{"2 OOM kill(s)"},
+		},
+		{
+			name:     "memory at the limit",
+			res:      resources.ResourceInfo{MemLimit: 100 * mi, MemUsage: 96 * mi},
+			score:    40,
+			severity: recommend.SeverityWarning,
+			reasons:  []string{"memory usage at 96% of limit"},
+		},
+		{
+			name:     "memory near the limit",
+			res:      resources.ResourceInfo{MemLimit: 100 * mi, MemUsage: 86 * mi},
+			score:    25,
+			severity: recommend.SeverityWarning,
+			reasons:  []string{"memory usage at 86% of limit"},
+		},
+		{
+			name:     "memory above requests",
+			res:      resources.ResourceInfo{MemRequest: 64 * mi, MemLimit: 100 * mi, MemUsage: 75 * mi},
+			score:    20,
+			severity: recommend.SeverityWarning,
+		},
+		{
+			name:     "cpu above the limit and requests",
+			res:      resources.ResourceInfo{CPURequest: 250, CPULimit: 500, CPUUsage: 600},
+			score:    25,
+			severity: recommend.SeverityWarning,
+			reasons:  []string{"CPU usage at 120% of limit"},
+		},
+		{
+			name:     "cpu near the limit",
+			res:      resources.ResourceInfo{CPULimit: 1000, CPUUsage: 950},
+			score:    10,
+			severity: recommend.SeverityInfo,
+		},
+		{
+			name:     "heavy throttling",
+			res:      resources.ResourceInfo{CPUThrottling: 0.3},
+			score:    20,
+			severity: recommend.SeverityWarning,
+			reasons:  []string{"CPU throttled 30% of periods"},
+		},
+		{
+			name:     "light throttling",
+			res:      resources.ResourceInfo{CPUThrottling: 0.1},
+			score:    10,
+			severity: recommend.SeverityInfo,
+		},
+		{
+			name:     "frequent restarts",
+			res:      resources.ResourceInfo{Restarts: 6},
+			score:    15,
+			severity: recommend.SeverityInfo,
+			reasons:  []string{"6 restarts"},
+		},
+		{
+			name:     "few restarts",
+			res:      resources.ResourceInfo{Restarts: 2},
+			score:    5,
+			severity: recommend.SeverityInfo,
+		},
+		{
+			name:     "pending pods counted once",
+			res:      resources.ResourceInfo{Events: []resources.PodEvent{pending, pending}},
+			score:    20,
+			severity: recommend.SeverityWarning,
+		},
+		{
+			name: "evicted by node pressure",
+			res: resources.ResourceInfo{Events: []resources.PodEvent{
+				{Pod: "web-0", Reason: resources.EventEvicted, Resources: []string{"memory"}, NodePressure: true},
+			}},
+			score:    20,
+			severity: recommend.SeverityWarning,
+		},
+		{
+			name: "evicted without node pressure",
+			res: resources.ResourceInfo{Events: []resources.PodEvent{
+				{Pod: "web-0", Reason: resources.EventEvicted},
+			}},
+			severity: recommend.SeverityInfo,
+		},
+		{
+			name:     "single replica",
+			res:      resources.ResourceInfo{Replicas: "1", OOMKills: 1},
+			score:    40,
+			severity: recommend.SeverityWarning,
+			reasons:  []string{"1 OOM kill(s)"},
+		},
+		{
+			name:     "critical",
+			res:      resources.ResourceInfo{Replicas: "2", MemLimit: 100 * mi, MemUsage: 96 * mi, OOMKills: 1},
+			score:    70,
+			severity: recommend.SeverityCritical,
+			reasons:  []string{"memory usage at 96% of limit", "1 OOM kill(s)"},
+		},
+		{
+			name: "clamped to 100",
+			res: resources.ResourceInfo{
+				Replicas: "1", MemLimit: 100 * mi, MemUsage: 99 * mi, OOMKills: 3, CPULimit: 500, CPUUsage: 500,
+				CPUThrottling: 0.5, Restarts: 10, Events: []resources.PodEvent{pending},
+			},
+			score:    100,
+			severity: recommend.SeverityCritical,
+			reasons: []string{
+				"memory usage at 99% of limit",
+				"3 OOM kill(s)",
+				"CPU usage at 100% of limit",
+				"CPU throttled 50% of periods",
+				"10 restarts",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := []resources.ResourceInfo{tt.res}
+
+			recommend.ScoreRisk(res)
+
+			assert.Equal(t, tt.score, res[0].RiskScore)
+			assert.Equal(t, tt.severity, res[0].Severity)
+			assert.Equal(t, tt.reasons, res[0].RiskReasons)
+		})
+	}
+}
@@ -23,62 +23,79 @@ const (
 	CPUHighMultiplier = 2.0
 	// CPUMinimumRequest defines the minimum CPU request in milli-cores (50m).
 	CPUMinimumRequest = 50
+	// CPURoundingStep defines the rounding step for CPU values less than 1 core (100m).
+	CPURoundingStep = 100
+	// CPULargeRoundingStep defines the rounding step for CPU values from 1 core (500m).
+	CPULargeRoundingStep = 500
+	// CPULargeRoundingFrom defines the CPU value in milli-cores from which the large rounding step is used (1 core).
+	CPULargeRoundingFrom = 1000
 	// MemoryLowMultiplier defines the multiplier for memory recommendation requests (20% increase).
 	MemoryLowMultiplier = 1.2
 	// MemoryHighMultiplier defines the multiplier for memory recommendation limits (100% increase).
 	MemoryHighMultiplier = 2.0
 	// MemoryMinimumRequest defines the minimum memory request in bytes (64Mi).
 	MemoryMinimumRequest = 64 * 1024 * 1024 // 64Mi
+	// MemoryRoundingStep defines the rounding step for memory values in bytes (128Mi).
+	MemoryRoundingStep = 128 * 1024 * 1024 // 128Mi
 )
 
-func roundUpCPULow(milliCores int64) int64 {
-	if milliCores <= CPUMinimumRequest {
-		return int64(CPUMinimumRequest)
-	}
-
-	increment := int64(500) // 500m
-	if milliCores < 1000 {
-		increment = 100 // 100m for values less than 1 core
-	}
-
-	target := int64(float64(milliCores) * CPULowMultiplier)
-
-	return ((target + increment - 1) / increment) * increment
+// sizing defines the headroom, rounding and caps of a single resource.
+// Values are in milli-cores for CPU and in bytes for memory.
+type sizing struct {
+	requestMultiplier float64
+	limitMultiplier   float64
+	minimum           int64
+	step              int64
+	largeStep         int64
+	largeStepFrom     int64
+	maxRequest        int64
+	maxLimit          int64
 }
 
-func roundUpCPUHigh(milliCores int64) int64 {
-	if milliCores <= CPUMinimumRequest {
-		return int64(CPUMinimumRequest)
+func defaultCPUSizing() sizing {
+	return sizing{
+		requestMultiplier: CPULowMultiplier,
+		limitMultiplier:   CPUHighMultiplier,
+		minimum:           CPUMinimumRequest,
+		step:              CPURoundingStep,
+		largeStep:         CPULargeRoundingStep,
+		largeStepFrom:     CPULargeRoundingFrom,
 	}
+}
 
-	increment := int64(500) // 500m
-	if milliCores < 1000 {
-		increment = 100 // 100m for values less than 1 core
+func defaultMemorySizing() sizing {
+	return sizing{
+		requestMultiplier: MemoryLowMultiplier,
+		limitMultiplier:   MemoryHighMultiplier,
+		minimum:           MemoryMinimumRequest,
+		step:              MemoryRoundingStep,
 	}
+}
 
-	target := int64(float64(milliCores) * CPUHighMultiplier)
+// request returns the recommended request for the usage.
+func (s sizing) request(usage int64) int64 {
+	return s.roundUp(usage, s.requestMultiplier)
+}
 
-	return ((target + increment - 1) / increment) * increment
+// limit returns the recommended limit for the usage.
+func (s sizing) limit(usage int64) int64 {
+	return s.roundUp(usage, s.limitMultiplier)
 }
 
-func roundUpMemoryLow(bytes int64) int64 {
-	if bytes <= MemoryMinimumRequest {
-		return MemoryMinimumRequest
+func (s sizing) roundUp(value int64, multiplier float64) int64 {
+	if value <= s.minimum {
+		return s.minimum
 	}
 
-	increment := int64(128 * 1024 * 1024) // 128Mi in bytes
-	target := int64(float64(bytes) * MemoryLowMultiplier)
-
-	return ((target + increment - 1) / increment) * increment
-}
-
-func roundUpMemoryHigh(bytes int64) int64 {
-	if bytes <= MemoryMinimumRequest {
-		return MemoryMinimumRequest
+	increment := s.step
+	if s.largeStep > 0 && value >= s.largeStepFrom {
+		increment = s.largeStep
 	}
 
-	increment := int64(128 * 1024 * 1024) // 128Mi in bytes
-	target := int64(float64(bytes) * MemoryHighMultiplier)
+	target := int64(float64(value) * multiplier)
+	if increment <= 0 {
+		return target
+	}
 
 	return ((target + increment - 1) / increment) * increment
 }
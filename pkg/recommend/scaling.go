@@ -0,0 +1,127 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+const (
+	// ScaleOutCPUThreshold is the CPU request per pod, in milli-cores, above which a stateless workload should scale out.
+	ScaleOutCPUThreshold = 1000
+	// ScaleOutMaxVariation is the highest standard deviation to average ratio of the CPU usage of the pods
+	// of a workload to scale out. When the load is uneven across pods, more replicas do not relieve the busy ones.
+	ScaleOutMaxVariation = 0.5
+)
+
+// adviseScaling suggests scaling stateless workloads out to more replicas with smaller CPU requests,
+// instead of raising the CPU request of every pod above ScaleOutCPUThreshold.
+// Only single container Deployments without an autoscaler are scaled out, other workloads scale up.
+func adviseScaling(res []resources.ResourceInfo, recs []resources.ResourceRecommendation, opts Options) {
+	containers := map[string]int{}
+	for _, r := range res {
+		containers[r.Kind+"/"+r.Name]++
+	}
+
+	for i := range recs {
+		rec := &recs[i]
+		if rec.RecommendedCPURequest <= max(rec.CurrentCPURequest, ScaleOutCPUThreshold) {
+			continue
+		}
+
+		r := containerInfo(res, rec)
+
+		switch {
+		case r.Kind == "StatefulSet":
+			rec.Notes = append(rec.Notes, "scale up, StatefulSet replicas are not interchangeable")
+		case r.Kind != "Deployment":
+		case r.Autoscaler != nil:
+			rec.Notes = append(rec.Notes, fmt.Sprintf("scale up, replicas are managed by %s %s", r.Autoscaler.Kind, r.Autoscaler.Name))
+		case containers[r.Kind+"/"+r.Name] > 1:
+			rec.Notes = append(rec.Notes, "scale up, the pod has several containers")
+		default:
+			if variation := podCPUVariation(r.Pods); variation > ScaleOutMaxVariation {
+				rec.Notes = append(rec.Notes, fmt.Sprintf("scale up, CPU usage is uneven across pods (stddev %.0f%% of average)", variation*100))
+
+				continue
+			}
+
+			cpu, _ := opts.Policy.sizingFor(r)
+			scaleOut(rec, cpu, replicaCount(r.Replicas))
+		}
+	}
+}
+
+// scaleOut spreads the recommended CPU request of all replicas over pods of ScaleOutCPUThreshold.
+func scaleOut(rec *resources.ResourceRecommendation, cpu sizing, replicas int64) {
+	total := rec.RecommendedCPURequest * replicas
+	count := (total + ScaleOutCPUThreshold - 1) / ScaleOutCPUThreshold
+
+	if count <= replicas {
+		return
+	}
+
+	request := cpu.roundUp((total+count-1)/count, 1)
+	if rec.RecommendedCPULimit > 0 {
+		rec.RecommendedCPULimit = cpu.roundUp(rec.RecommendedCPULimit*request/rec.RecommendedCPURequest, 1)
+	}
+
+	rec.Notes = append(rec.Notes, fmt.Sprintf("scale out to %d replicas at %s CPU instead of %s per pod",
+		count, formatMilliCores(request), formatMilliCores(rec.RecommendedCPURequest)))
+
+	setRecommended(&rec.RecommendedCPURequest, rec.CurrentCPURequest, request)
+	rec.RecommendedReplicas = int32(count)
+}
+
+// podCPUVariation returns the coefficient of variation of the CPU usage of the pods,
+// the standard deviation to average ratio, 0 with less than two pods.
+func podCPUVariation(pods []resources.PodUsage) float64 {
+	if len(pods) < 2 {
+		return 0
+	}
+
+	var sum float64
+	for _, p := range pods {
+		sum += float64(p.CPU)
+	}
+
+	mean := sum / float64(len(pods))
+	if mean <= 0 {
+		return 0
+	}
+
+	var variance float64
+	for _, p := range pods {
+		variance += (float64(p.CPU) - mean) * (float64(p.CPU) - mean)
+	}
+
+	return math.Sqrt(variance/float64(len(pods))) / mean
+}
+
+// containerInfo returns the resource information of the recommended container.
+func containerInfo(res []resources.ResourceInfo, rec *resources.ResourceRecommendation) resources.ResourceInfo {
+	for _, r := range res {
+		if r.Kind == rec.Kind && r.Name == rec.Name && r.Container == rec.Container {
+			return r
+		}
+	}
+
+	return resources.ResourceInfo{}
+}
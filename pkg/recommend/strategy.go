@@ -0,0 +1,249 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+const (
+	// StrategyDefault sizes requests and limits from the aggregated usage with the policy headroom.
+	StrategyDefault = "default"
+	// StrategyLatencySensitive sizes requests from high percentiles and does not limit CPU.
+	StrategyLatencySensitive = "latency-sensitive"
+	// StrategyCostOptimized sizes requests from the median CPU and p95 memory usage and does not limit CPU.
+	StrategyCostOptimized = "cost-optimized"
+	// StrategyMeanStdDev sizes requests and limits as mean + k·stddev of the usage.
+	StrategyMeanStdDev = "mean-stddev"
+	// StrategyVPA takes requests from the VPA target and the memory limit from the VPA upper bound.
+	StrategyVPA = "vpa"
+)
+
+// Estimator selects the usage statistic used to size a request or a limit.
+type Estimator struct {
+	// Statistic is the usage statistic, e.g. "p95", "max" or "avg". Empty uses the aggregated usage.
+	Statistic string `json:"statistic,omitempty"`
+	// StdDevs adds k standard deviations to the statistic, e.g. "avg" with 2 is mean + 2·stddev.
+	StdDevs float64 `json:"stdDevs,omitempty"`
+	// Multiplier is the headroom over the statistic, it replaces the policy multiplier when set.
+	Multiplier float64 `json:"multiplier,omitempty"`
+}
+
+// Strategy defines how requests and limits are derived from the usage statistics.
+// A nil limit estimator means the strategy does not recommend the limit.
+type Strategy struct {
+	CPURequest Estimator  `json:"cpuRequest"`
+	CPULimit   *Estimator `json:"cpuLimit,omitempty"`
+	MemRequest Estimator  `json:"memoryRequest"`
+	MemLimit   *Estimator `json:"memoryLimit,omitempty"`
+}
+
+// BuiltinStrategies returns the built-in sizing strategies by name.
+func BuiltinStrategies() map[string]Strategy {
+	return map[string]Strategy{
+		StrategyDefault: {
+			CPULimit: &Estimator{},
+			MemLimit: &Estimator{},
+		},
+		StrategyLatencySensitive: {
+			CPURequest: Estimator{Statistic: "p95"},
+			MemRequest: Estimator{Statistic: "p99"},
+			MemLimit:   &Estimator{Statistic: resources.StatisticMax, Multiplier: 1.5},
+		},
+		StrategyCostOptimized: {
+			CPURequest: Estimator{Statistic: "p50", Multiplier: 1},
+			MemRequest: Estimator{Statistic: "p95", Multiplier: 1},
+			MemLimit:   &Estimator{Statistic: "p99", Multiplier: 1.1},
+		},
+		StrategyMeanStdDev: {
+			CPURequest: Estimator{Statistic: resources.StatisticAvg, StdDevs: 2, Multiplier: 1},
+			CPULimit:   &Estimator{Statistic: resources.StatisticAvg, StdDevs: 4, Multiplier: 1},
+			MemRequest: Estimator{Statistic: resources.StatisticAvg, StdDevs: 2, Multiplier: 1},
+			MemLimit:   &Estimator{Statistic: resources.StatisticAvg, StdDevs: 4, Multiplier: 1},
+		},
+		StrategyVPA: {
+			CPURequest: Estimator{Statistic: resources.StatisticVPATarget, Multiplier: 1},
+			MemRequest: Estimator{Statistic: resources.StatisticVPATarget, Multiplier: 1},
+			MemLimit:   &Estimator{Statistic: resources.StatisticVPAUpperBound, Multiplier: 1},
+		},
+	}
+}
+
+// Strategy returns the strategy by name, strategies of the policy take precedence over the built-in ones.
+func (p *Policy) Strategy(name string) (*Strategy, error) {
+	if name == "" {
+		name = StrategyDefault
+	}
+
+	if p != nil {
+		if s, ok := p.Strategies[name]; ok {
+			return &s, nil
+		}
+	}
+
+	if s, ok := BuiltinStrategies()[name]; ok {
+		return &s, nil
+	}
+
+	return nil, fmt.Errorf("unknown strategy %q, available strategies: %s", name, strings.Join(p.StrategyNames(), ", "))
+}
+
+// Statistics returns the usage statistics the strategy needs from the metrics source.
+func (s *Strategy) Statistics() []string {
+	var stats []string
+
+	for _, e := range s.estimators() {
+		if e.Statistic != "" {
+			stats = appendUnique(stats, e.Statistic)
+		}
+
+		if e.StdDevs > 0 {
+			stats = appendUnique(stats, resources.StatisticStdDev)
+		}
+	}
+
+	sort.Strings(stats)
+
+	return stats
+}
+
+// usesVPA reports whether the strategy sizes any request or limit from the VPA recommendation.
+func (s *Strategy) usesVPA() bool {
+	return slices.ContainsFunc(s.estimators(), func(e Estimator) bool {
+		return resources.IsVPAStatistic(e.Statistic)
+	})
+}
+
+func (s *Strategy) validate(name string) error {
+	for _, e := range s.estimators() {
+		if e.Statistic != "" && !resources.ValidStatistic(e.Statistic) {
+			return fmt.Errorf("strategies.%s: unknown statistic %q", name, e.Statistic)
+		}
+
+		if e.StdDevs < 0 || e.Multiplier < 0 {
+			return fmt.Errorf("strategies.%s: stdDevs and multiplier must be positive", name)
+		}
+	}
+
+	return nil
+}
+
+func (s *Strategy) estimators() []Estimator {
+	estimators := []Estimator{s.CPURequest, s.MemRequest}
+
+	for _, e := range []*Estimator{s.CPULimit, s.MemLimit} {
+		if e != nil {
+			estimators = append(estimators, *e)
+		}
+	}
+
+	return estimators
+}
+
+// estimate is the strategy estimate of a resource before headroom and rounding.
+type estimate struct {
+	request    int64
+	hasRequest bool
+	limit      int64
+	hasLimit   bool
+}
+
+// estimateResource computes the request and limit estimates of a resource from its usage and statistics,
+// and returns the sizing with the strategy multipliers applied.
+func estimateResource(s sizing, request Estimator, limit *Estimator, usage int64, stats map[string]int64) (estimate, sizing) {
+	var est estimate
+
+	est.request, est.hasRequest = request.value(usage, stats)
+	if request.Multiplier > 0 {
+		s.requestMultiplier = request.Multiplier
+	}
+
+	if limit != nil {
+		est.limit, est.hasLimit = limit.value(usage, stats)
+
+		if limit.Multiplier > 0 {
+			s.limitMultiplier = limit.Multiplier
+		}
+	}
+
+	return est, s
+}
+
+// withVPA returns the usage statistics together with the VPA recommendation statistics.
+func withVPA(stats, vpa map[string]int64) map[string]int64 {
+	if len(vpa) == 0 {
+		return stats
+	}
+
+	merged := make(map[string]int64, len(stats)+len(vpa))
+	maps.Copy(merged, stats)
+	maps.Copy(merged, vpa)
+
+	return merged
+}
+
+// value returns the statistic and whether there is data for it. A statistic the metrics source does not report
+// falls back to the aggregated usage, except the VPA statistics, which have no data without a VPA recommendation.
+func (e Estimator) value(usage int64, stats map[string]int64) (int64, bool) {
+	result := usage
+
+	if e.Statistic != "" {
+		v, ok := stats[e.Statistic]
+
+		switch {
+		case ok && v > 0:
+			result = v
+		case resources.IsVPAStatistic(e.Statistic):
+			return 0, false
+		}
+	}
+
+	if e.StdDevs > 0 {
+		if stddev, ok := stats[resources.StatisticStdDev]; ok {
+			result += int64(e.StdDevs * float64(stddev))
+		}
+	}
+
+	return result, result > 0
+}
+
+// StrategyNames returns the names of the built-in and policy strategies.
+func (p *Policy) StrategyNames() []string {
+	names := make([]string, 0, len(BuiltinStrategies()))
+
+	for name := range BuiltinStrategies() {
+		names = append(names, name)
+	}
+
+	if p != nil {
+		for name := range p.Strategies {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
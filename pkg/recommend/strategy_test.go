@@ -0,0 +1,116 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergelogvinov/helm-resources/pkg/recommend"
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+func TestEstimatorValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		estimator recommend.Estimator
+		usage     int64
+		stats     map[string]int64
+		expect    int64
+		ok        bool
+	}{
+		{
+			name:   "aggregated usage",
+			usage:  120,
+			stats:  map[string]int64{"p95": 300},
+			expect: 120,
+			ok:     true,
+		},
+		{
+			name:      "percentile",
+			estimator: recommend.Estimator{Statistic: "p95"},
+			usage:     120,
+			stats:     map[string]int64{"p95": 300, "max": 500},
+			expect:    300,
+			ok:        true,
+		},
+		{
+			name:      "missing statistic falls back to the usage",
+			estimator: recommend.Estimator{Statistic: "p99.9"},
+			usage:     120,
+			stats:     map[string]int64{"p95": 300},
+			expect:    120,
+			ok:        true,
+		},
+		{
+			name:      "mean plus two stddev",
+			estimator: recommend.Estimator{Statistic: resources.StatisticAvg, StdDevs: 2},
+			usage:     120,
+			stats:     map[string]int64{"avg": 100, "stddev": 15},
+			expect:    130,
+			ok:        true,
+		},
+		{
+			name:      "mean plus fractional stddev",
+			estimator: recommend.Estimator{Statistic: resources.StatisticAvg, StdDevs: 1.5},
+			usage:     120,
+			stats:     map[string]int64{"avg": 256, "stddev": 64},
+			expect:    352,
+			ok:        true,
+		},
+		{
+			name:      "stddev without the statistic",
+			estimator: recommend.Estimator{Statistic: resources.StatisticAvg, StdDevs: 2},
+			usage:     120,
+			stats:     map[string]int64{"avg": 100},
+			expect:    100,
+			ok:        true,
+		},
+		{
+			name:      "vpa target",
+			estimator: recommend.Estimator{Statistic: resources.StatisticVPATarget},
+			usage:     120,
+			stats:     map[string]int64{resources.StatisticVPATarget: 230},
+			expect:    230,
+			ok:        true,
+		},
+		{
+			name:      "vpa target without a vpa",
+			estimator: recommend.Estimator{Statistic: resources.StatisticVPATarget},
+			usage:     120,
+			stats:     map[string]int64{"p95": 300},
+		},
+		{
+			name:      "vpa upper bound without a vpa",
+			estimator: recommend.Estimator{Statistic: resources.StatisticVPAUpperBound},
+			usage:     120,
+		},
+		{
+			name:      "no usage",
+			estimator: recommend.Estimator{Statistic: "p95"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := recommend.EstimatorValue(tt.estimator, tt.usage, tt.stats)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expect, value)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// KEDA defaults of the ScaledObject spec.
+	kedaDefaultMinReplicas = 0
+	kedaDefaultMaxReplicas = 100
+)
+
+// scaledObject is the part of the KEDA ScaledObject used to find the workload and its CPU target.
+type scaledObject struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		ScaleTargetRef struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"scaleTargetRef"`
+		MinReplicaCount *int32 `json:"minReplicaCount"`
+		MaxReplicaCount *int32 `json:"maxReplicaCount"`
+		Triggers        []struct {
+			Type       string            `json:"type"`
+			MetricType string            `json:"metricType"`
+			Metadata   map[string]string `json:"metadata"`
+		} `json:"triggers"`
+	} `json:"spec"`
+}
+
+// targetKind returns the kind of the scaled workload, Deployment when it is not set.
+func (so *scaledObject) targetKind() string {
+	if so.Spec.ScaleTargetRef.Kind == "" {
+		return "Deployment"
+	}
+
+	return so.Spec.ScaleTargetRef.Kind
+}
+
+type scaledObjectList struct {
+	Items []scaledObject `json:"items"`
+}
+
+// attachAutoscalers looks up HorizontalPodAutoscalers and KEDA ScaledObjects of the namespace
+// and attaches them to the resource rows of the workloads they scale.
+// ScaledObjects take precedence over the HPAs KEDA creates for them.
+func attachAutoscalers(ctx context.Context, clientset kubernetes.Interface, namespace string, res []resources.ResourceInfo) {
+	if len(res) == 0 {
+		return
+	}
+
+	hpas, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err == nil {
+		for _, hpa := range hpas.Items {
+			setAutoscaler(res, hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name, hpaAutoscaler(&hpa))
+		}
+	}
+
+	restClient := clientset.Discovery().RESTClient()
+	if restClient == nil {
+		return
+	}
+
+	data, err := restClient.Get().AbsPath("/apis/keda.sh/v1alpha1/namespaces", namespace, "scaledobjects").DoRaw(ctx)
+	if err != nil {
+		return
+	}
+
+	var list scaledObjectList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return
+	}
+
+	for _, so := range list.Items {
+		setAutoscaler(res, so.targetKind(), so.Spec.ScaleTargetRef.Name, kedaAutoscaler(&so, res))
+	}
+}
+
+func setAutoscaler(res []resources.ResourceInfo, kind, name string, autoscaler *resources.Autoscaler) {
+	for i := range res {
+		if res[i].Kind == kind && res[i].Name == name {
+			res[i].Autoscaler = autoscaler
+		}
+	}
+}
+
+func hpaAutoscaler(hpa *autoscalingv2.HorizontalPodAutoscaler) *resources.Autoscaler {
+	a := &resources.Autoscaler{
+		Kind:            resources.AutoscalerHPA,
+		Name:            hpa.Name,
+		MinReplicas:     1,
+		MaxReplicas:     hpa.Spec.MaxReplicas,
+		CurrentReplicas: hpa.Status.CurrentReplicas,
+	}
+
+	if hpa.Spec.MinReplicas != nil {
+		a.MinReplicas = *hpa.Spec.MinReplicas
+	}
+
+	for _, m := range hpa.Spec.Metrics {
+		switch {
+		case m.Type == autoscalingv2.ResourceMetricSourceType && m.Resource != nil &&
+			m.Resource.Name == v1.ResourceCPU && m.Resource.Target.AverageUtilization != nil:
+			a.CPUUtilization = *m.Resource.Target.AverageUtilization
+		case m.Type == autoscalingv2.ContainerResourceMetricSourceType && m.ContainerResource != nil &&
+			m.ContainerResource.Name == v1.ResourceCPU && m.ContainerResource.Target.AverageUtilization != nil:
+			a.CPUUtilization = *m.ContainerResource.Target.AverageUtilization
+			a.CPUContainer = m.ContainerResource.Container
+		}
+	}
+
+	return a
+}
+
+// kedaAutoscaler returns the autoscaler of the ScaledObject, the current replicas are taken
+// from the HPA KEDA manages for it.
+func kedaAutoscaler(so *scaledObject, res []resources.ResourceInfo) *resources.Autoscaler {
+	a := &resources.Autoscaler{
+		Kind:        resources.AutoscalerKEDA,
+		Name:        so.Metadata.Name,
+		MinReplicas: kedaDefaultMinReplicas,
+		MaxReplicas: kedaDefaultMaxReplicas,
+	}
+
+	if so.Spec.MinReplicaCount != nil {
+		a.MinReplicas = *so.Spec.MinReplicaCount
+	}
+
+	if so.Spec.MaxReplicaCount != nil {
+		a.MaxReplicas = *so.Spec.MaxReplicaCount
+	}
+
+	for _, r := range res {
+		if r.Kind == so.targetKind() && r.Name == so.Spec.ScaleTargetRef.Name && r.Autoscaler != nil {
+			a.CurrentReplicas = r.Autoscaler.CurrentReplicas
+		}
+	}
+
+	for _, t := range so.Spec.Triggers {
+		if t.Type != "cpu" {
+			continue
+		}
+
+		metricType := t.MetricType
+		if metricType == "" {
+			metricType = t.Metadata["type"]
+		}
+
+		if metricType != "" && metricType != string(autoscalingv2.UtilizationMetricType) {
+			continue
+		}
+
+		if value, err := strconv.ParseInt(t.Metadata["value"], 10, 32); err == nil {
+			a.CPUUtilization = int32(value)
+			a.CPUContainer = t.Metadata["containerName"]
+		}
+	}
+
+	return a
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+	"github.com/sergelogvinov/helm-resources/pkg/resources/apps"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestHPAAutoscaler(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		expect   *resources.Autoscaler
+	}{
+		{
+			name: "cpu utilization",
+			manifest: `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+spec:
+  scaleTargetRef: {apiVersion: apps/v1, kind: Deployment, name: web}
+  minReplicas: 2
+  maxReplicas: 10
+  metrics:
+    - type: Resource
+      resource: {name: memory, target: {type: Utilization, averageUtilization: 80}}
+    - type: Resource
+      resource: {name: cpu, target: {type: Utilization, averageUtilization: 70}}
+status:
+  currentReplicas: 4
+`,
+			expect: &resources.Autoscaler{
+				Kind: resources.AutoscalerHPA, Name: "web", MinReplicas: 2, MaxReplicas: 10, CurrentReplicas: 4, CPUUtilization: 70,
+			},
+		},
+		{
+			name: "container cpu utilization and default min replicas",
+			manifest: `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: api
+spec:
+  scaleTargetRef: {apiVersion: apps/v1, kind: Deployment, name: api}
+  maxReplicas: 5
+  metrics:
+    - type: ContainerResource
+      containerResource: {name: cpu, container: api, target: {type: Utilization, averageUtilization: 60}}
+`,
+			expect: &resources.Autoscaler{
+				Kind: resources.AutoscalerHPA, Name: "api", MinReplicas: 1, MaxReplicas: 5, CPUUtilization: 60, CPUContainer: "api",
+			},
+		},
+		{
+			name: "cpu average value",
+			manifest: `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: worker
+spec:
+  scaleTargetRef: {apiVersion: apps/v1, kind: StatefulSet, name: worker}
+  minReplicas: 3
+  maxReplicas: 6
+  metrics:
+    - type: Resource
+      resource: {name: cpu, target: {type: AverageValue, averageValue: 500m}}
+status:
+  currentReplicas: 3
+`,
+			expect: &resources.Autoscaler{
+				Kind: resources.AutoscalerHPA, Name: "worker", MinReplicas: 3, MaxReplicas: 6, CurrentReplicas: 3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hpa autoscalingv2.HorizontalPodAutoscaler
+			assert.NoError(t, yaml.Unmarshal([]byte(tt.manifest), &hpa))
+
+			assert.Equal(t, tt.expect, apps.HPAAutoscaler(&hpa))
+		})
+	}
+}
+
+func TestKEDAAutoscaler(t *testing.T) {
+	// The rows carry the HPAs KEDA creates for the ScaledObjects.
+	res := []resources.ResourceInfo{
+		{Kind: "Deployment", Name: "web", Container: "web", Autoscaler: &resources.Autoscaler{CurrentReplicas: 3}},
+		{Kind: "StatefulSet", Name: "web", Container: "web", Autoscaler: &resources.Autoscaler{CurrentReplicas: 7}},
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+		expect   *resources.Autoscaler
+	}{
+		{
+			name: "default replica counts and target kind",
+			manifest: `
+apiVersion: keda.sh/v1alpha1
+kind: ScaledObject
+metadata:
+  name: web
+spec:
+  scaleTargetRef: {name: web}
+  triggers:
+    - type: cpu
+      metricType: Utilization
+      metadata: {value: "75"}
+`,
+			expect: &resources.Autoscaler{
+				Kind: resources.AutoscalerKEDA, Name: "web", MinReplicas: 0, MaxReplicas: 100, CurrentReplicas: 3, CPUUtilization: 75,
+			},
+		},
+		{
+			name: "statefulset target with replica counts",
+			manifest: `
+apiVersion: keda.sh/v1alpha1
+kind: ScaledObject
+metadata:
+  name: web-sts
+spec:
+  scaleTargetRef: {apiVersion: apps/v1, kind: StatefulSet, name: web}
+  minReplicaCount: 2
+  maxReplicaCount: 12
+  triggers:
+    - type: prometheus
+      metadata: {query: "sum(rate(http_requests_total[1m]))", threshold: "100"}
+    - type: cpu
+      metadata: {type: Utilization, value: "60", containerName: web}
+`,
+			expect: &resources.Autoscaler{
+				Kind: resources.AutoscalerKEDA, Name: "web-sts", MinReplicas: 2, MaxReplicas: 12, CurrentReplicas: 7,
+				CPUUtilization: 60, CPUContainer: "web",
+			},
+		},
+		{
+			name: "cpu average value trigger",
+			manifest: `
+apiVersion: keda.sh/v1alpha1
+kind: ScaledObject
+metadata:
+  name: api
+spec:
+  scaleTargetRef: {name: api}
+  minReplicaCount: 1
+  triggers:
+    - type: cpu
+      metricType: AverageValue
+      metadata: {value: "500m"}
+`,
+			expect: &resources.Autoscaler{
+				Kind: resources.AutoscalerKEDA, Name: "api", MinReplicas: 1, MaxReplicas: 100,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			autoscaler, err := apps.KEDAAutoscaler(tt.manifest, res)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, autoscaler)
+		})
+	}
+}
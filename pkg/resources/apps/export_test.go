@@ -0,0 +1,36 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	"sigs.k8s.io/yaml"
+)
+
+// HPAAutoscaler exposes hpaAutoscaler to the tests.
+var HPAAutoscaler = hpaAutoscaler
+
+// KEDAAutoscaler exposes kedaAutoscaler to the tests, decoding the ScaledObject manifest.
+func KEDAAutoscaler(manifest string, res []resources.ResourceInfo) (*resources.Autoscaler, error) {
+	var so scaledObject
+	if err := yaml.Unmarshal([]byte(manifest), &so); err != nil {
+		return nil, err
+	}
+
+	return kedaAutoscaler(&so, res), nil
+}
@@ -20,6 +20,7 @@ package apps
 import (
 	"context"
 	"fmt"
+	"maps"
 	"strings"
 
 	"helm.sh/helm/v3/pkg/release"
@@ -27,6 +28,7 @@ import (
 	"github.com/sergelogvinov/helm-resources/pkg/metrics"
 	"github.com/sergelogvinov/helm-resources/pkg/resources"
 	crds "github.com/sergelogvinov/helm-resources/pkg/resources/crds"
+	"github.com/sergelogvinov/helm-resources/pkg/resources/signals"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -45,10 +47,10 @@ const unknown = "unknown"
 func ExtractResourcesFromHelmRelease(
 	ctx context.Context,
 	clientset *kubernetes.Clientset,
-	metricsClient *metrics.Client,
+	metricsProvider metrics.Provider,
 	release *release.Release,
 ) ([]resources.ResourceInfo, error) {
-	var res []resources.ResourceInfo
+	var res, crdRes []resources.ResourceInfo
 
 	namespace := release.Namespace
 	chartName := ""
@@ -74,18 +76,18 @@ func ExtractResourcesFromHelmRelease(
 		standardWorkload := ((kind == "Deployment" || kind == "StatefulSet" || kind == "DaemonSet") && apiVersion == "apps/v1") ||
 			(kind == "CronJob" && apiVersion == "batch/v1")
 		if !standardWorkload {
-			resCRD, err := crds.ExtractResourcesFromCRD(ctx, clientset, metricsClient, release.Name, doc, namespace)
+			resCRD, err := crds.ExtractResourcesFromCRD(ctx, clientset, metricsProvider, release.Name, doc, namespace)
 			if err != nil {
 				continue
 			}
 
-			res = append(res, resCRD...)
+			crdRes = append(crdRes, resCRD...)
 
 			continue
 		}
 
 		var (
-			containers   []v1.Container
+			podSpec      *v1.PodSpec
 			workloadName string
 			replicas     string
 			labels       map[string]string
@@ -98,7 +100,7 @@ func ExtractResourcesFromHelmRelease(
 				continue
 			}
 
-			containers = deployment.Spec.Template.Spec.Containers
+			podSpec = &deployment.Spec.Template.Spec
 			workloadName = deployment.Name
 
 			deployObj, err := clientset.AppsV1().Deployments(namespace).Get(ctx, deployment.Name, metav1.GetOptions{})
@@ -114,7 +116,7 @@ func ExtractResourcesFromHelmRelease(
 				continue
 			}
 
-			containers = statefulSet.Spec.Template.Spec.Containers
+			podSpec = &statefulSet.Spec.Template.Spec
 			workloadName = statefulSet.Name
 
 			stsObj, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, statefulSet.Name, metav1.GetOptions{})
@@ -130,7 +132,7 @@ func ExtractResourcesFromHelmRelease(
 				continue
 			}
 
-			containers = daemonSet.Spec.Template.Spec.Containers
+			podSpec = &daemonSet.Spec.Template.Spec
 			workloadName = daemonSet.Name
 
 			dsObj, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, daemonSet.Name, metav1.GetOptions{})
@@ -146,7 +148,7 @@ func ExtractResourcesFromHelmRelease(
 				continue
 			}
 
-			containers = cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers
+			podSpec = &cronJob.Spec.JobTemplate.Spec.Template.Spec
 			workloadName = cronJob.Name
 
 			cronJobObj, err := clientset.BatchV1().CronJobs(namespace).Get(ctx, cronJob.Name, metav1.GetOptions{})
@@ -158,17 +160,34 @@ func ExtractResourcesFromHelmRelease(
 			}
 		}
 
+		workloadLabels := maps.Clone(labels)
+		if workloadLabels == nil {
+			workloadLabels = map[string]string{}
+		}
+
+		maps.Copy(workloadLabels, obj.GetLabels())
+
 		labels = resources.FilterLabels(labels)
 
-		for _, container := range containers {
+		if podSpec == nil {
+			continue
+		}
+
+		scheduling := resources.SchedulingFromPodSpec(podSpec)
+		initContainers := resources.InitContainerResources(podSpec)
+
+		for _, container := range podSpec.Containers {
 			resInfo := resources.ResourceInfo{
-				Chart:     chartName,
-				Release:   release.Name,
-				Kind:      kind,
-				Name:      workloadName,
-				Replicas:  replicas,
-				Container: container.Name,
-				Labels:    labels,
+				Chart:          chartName,
+				Release:        release.Name,
+				Kind:           kind,
+				Name:           workloadName,
+				Replicas:       replicas,
+				Container:      container.Name,
+				Labels:         labels,
+				WorkloadLabels: workloadLabels,
+				Scheduling:     scheduling,
+				InitContainers: initContainers,
 			}
 
 			if container.Resources.Requests != nil {
@@ -191,14 +210,17 @@ func ExtractResourcesFromHelmRelease(
 				}
 			}
 
-			cpuUsage, memUsage := metricsClient.GetContainerMetrics(ctx, namespace, resInfo)
-
-			resInfo.CPUUsage = cpuUsage
-			resInfo.MemUsage = memUsage
+			resInfo.SetUsage(metricsProvider.GetContainerUsage(ctx, namespace, resInfo))
 
 			res = append(res, resInfo)
 		}
 	}
 
+	res = append(res, crdRes...)
+
+	signals.Attach(ctx, clientset, namespace, res)
+	attachAutoscalers(ctx, clientset, namespace, res)
+	resources.SetQoSClass(res)
+
 	return res, nil
 }
@@ -33,7 +33,7 @@ import (
 func extractClickHouseInstallationResources(
 	ctx context.Context,
 	_ *kubernetes.Clientset,
-	metricsClient *metrics.Client,
+	metricsProvider metrics.Provider,
 	release string,
 	obj unstructured.Unstructured,
 	namespace string,
@@ -71,11 +71,12 @@ func extractClickHouseInstallationResources(
 	}
 
 	resInfo := resources.ResourceInfo{
-		Release:   release,
-		Kind:      "ClickHouseInstallation",
-		Name:      installationName,
-		Replicas:  replicas,
-		Container: "clickhouse",
+		Release:        release,
+		Kind:           "ClickHouseInstallation",
+		Name:           installationName,
+		Replicas:       replicas,
+		Container:      "clickhouse",
+		WorkloadLabels: obj.GetLabels(),
 	}
 
 	podTemplates, found, err := unstructured.NestedSlice(obj.Object, "spec", "templates", "podTemplates")
@@ -102,9 +103,7 @@ func extractClickHouseInstallationResources(
 	res := resInfo
 	res.Name = "chi-" + installationName + "-" + clusterName
 
-	cpuUsage, memUsage := metricsClient.GetContainerMetrics(ctx, namespace, res)
-	resInfo.CPUUsage = cpuUsage
-	resInfo.MemUsage = memUsage
+	resInfo.SetUsage(metricsProvider.GetContainerUsage(ctx, namespace, res))
 
 	return []resources.ResourceInfo{resInfo}, nil
 }
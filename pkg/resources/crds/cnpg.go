@@ -31,7 +31,7 @@ import (
 func extractCNPGClusterResources(
 	ctx context.Context,
 	_ *kubernetes.Clientset,
-	metricsClient *metrics.Client,
+	metricsProvider metrics.Provider,
 	release string,
 	obj unstructured.Unstructured,
 	namespace string,
@@ -44,11 +44,12 @@ func extractCNPGClusterResources(
 	}
 
 	resInfo := resources.ResourceInfo{
-		Release:   release,
-		Kind:      "Cluster",
-		Name:      clusterName,
-		Replicas:  replicas,
-		Container: "postgres",
+		Release:        release,
+		Kind:           "Cluster",
+		Name:           clusterName,
+		Replicas:       replicas,
+		Container:      "postgres",
+		WorkloadLabels: obj.GetLabels(),
 	}
 
 	resourcesSpec, ok, err := unstructured.NestedMap(obj.Object, "spec", "resources")
@@ -60,9 +61,7 @@ func extractCNPGClusterResources(
 		extractContainerResources(resourcesSpec, &resInfo)
 	}
 
-	cpuUsage, memUsage := metricsClient.GetContainerMetrics(ctx, namespace, resInfo)
-	resInfo.CPUUsage = cpuUsage
-	resInfo.MemUsage = memUsage
+	resInfo.SetUsage(metricsProvider.GetContainerUsage(ctx, namespace, resInfo))
 
 	return []resources.ResourceInfo{resInfo}, nil
 }
@@ -71,7 +70,7 @@ func extractCNPGClusterResources(
 func extractCNPGPoolerResources(
 	ctx context.Context,
 	_ *kubernetes.Clientset,
-	metricsClient *metrics.Client,
+	metricsProvider metrics.Provider,
 	release string,
 	obj unstructured.Unstructured,
 	namespace string,
@@ -84,11 +83,12 @@ func extractCNPGPoolerResources(
 	}
 
 	resInfo := resources.ResourceInfo{
-		Release:   release,
-		Kind:      "Pooler",
-		Name:      poolerName,
-		Replicas:  replicas,
-		Container: "pgbouncer",
+		Release:        release,
+		Kind:           "Pooler",
+		Name:           poolerName,
+		Replicas:       replicas,
+		Container:      "pgbouncer",
+		WorkloadLabels: obj.GetLabels(),
 	}
 
 	containers, ok, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
@@ -108,9 +108,7 @@ func extractCNPGPoolerResources(
 		}
 	}
 
-	cpuUsage, memUsage := metricsClient.GetContainerMetrics(ctx, namespace, resInfo)
-	resInfo.CPUUsage = cpuUsage
-	resInfo.MemUsage = memUsage
+	resInfo.SetUsage(metricsProvider.GetContainerUsage(ctx, namespace, resInfo))
 
 	return []resources.ResourceInfo{resInfo}, nil
 }
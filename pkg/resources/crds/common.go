@@ -31,10 +31,11 @@ import (
 const unknown = "unknown"
 
 // ExtractResourcesFromCRD extracts resource information from a Custom Resource Definition (CRD) manifest for supported workloads.
+// The pod signals are attached by the caller, once for all workloads of the release.
 func ExtractResourcesFromCRD(
 	ctx context.Context,
 	clientset *kubernetes.Clientset,
-	metricsClient *metrics.Client,
+	metricsProvider metrics.Provider,
 	release string,
 	manifest string,
 	namespace string,
@@ -49,14 +50,16 @@ func ExtractResourcesFromCRD(
 	kind := obj.GetKind()
 	apiVersion := obj.GetAPIVersion()
 
+	var err error
+
 	switch apiVersion + "/" + kind { //nolint:gocritic
 	case "postgresql.cnpg.io/v1/Cluster":
-		return extractCNPGClusterResources(ctx, clientset, metricsClient, release, obj, namespace)
+		res, err = extractCNPGClusterResources(ctx, clientset, metricsProvider, release, obj, namespace)
 	case "postgresql.cnpg.io/v1/Pooler":
-		return extractCNPGPoolerResources(ctx, clientset, metricsClient, release, obj, namespace)
+		res, err = extractCNPGPoolerResources(ctx, clientset, metricsProvider, release, obj, namespace)
 	case "clickhouse.altinity.com/v1/ClickHouseInstallation":
-		return extractClickHouseInstallationResources(ctx, clientset, metricsClient, release, obj, namespace)
+		res, err = extractClickHouseInstallationResources(ctx, clientset, metricsProvider, release, obj, namespace)
 	}
 
-	return res, nil
+	return res, err
 }
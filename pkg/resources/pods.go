@@ -0,0 +1,124 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"slices"
+)
+
+const (
+	// OutlierFactor is the usage to median usage ratio above which a pod is an outlier.
+	OutlierFactor = 2.0
+	// OutlierMinPods is the lowest number of pods to look for outliers.
+	OutlierMinPods = 3
+)
+
+// PodUsage represents the observed usage of the container in a single pod.
+type PodUsage struct {
+	Pod string `json:"pod"`
+	CPU int64  `json:"cpu_usage,omitempty"`    // millicores
+	Mem int64  `json:"memory_usage,omitempty"` // bytes
+	// Outlier is set when the pod uses more than OutlierFactor times the median usage of the pods
+	Outlier bool `json:"outlier,omitempty"`
+}
+
+// SizedForBusiestPod reports whether the workload kind is sized for its busiest pod rather than the average one.
+// StatefulSet ordinals and DaemonSet pods do not share the load evenly, so the average hides the hot pod.
+func SizedForBusiestPod(kind string) bool {
+	return kind == "StatefulSet" || kind == "DaemonSet"
+}
+
+// BusiestPod returns the pod with the highest CPU and the pod with the highest memory usage.
+func BusiestPod(pods []PodUsage) (PodUsage, PodUsage) {
+	var cpu, mem PodUsage
+
+	for _, p := range pods {
+		if p.CPU > cpu.CPU {
+			cpu = p
+		}
+
+		if p.Mem > mem.Mem {
+			mem = p
+		}
+	}
+
+	return cpu, mem
+}
+
+// Distribution represents the usage distribution across pods.
+type Distribution struct {
+	Min    int64
+	Median int64
+	Max    int64
+}
+
+// CPUDistribution returns the CPU usage distribution across pods.
+func CPUDistribution(pods []PodUsage) Distribution {
+	return distribution(pods, func(p PodUsage) int64 { return p.CPU })
+}
+
+// MemDistribution returns the memory usage distribution across pods.
+func MemDistribution(pods []PodUsage) Distribution {
+	return distribution(pods, func(p PodUsage) int64 { return p.Mem })
+}
+
+// MarkOutliers flags the pods using more than OutlierFactor times the median CPU or memory usage.
+func MarkOutliers(pods []PodUsage) {
+	if len(pods) < OutlierMinPods {
+		return
+	}
+
+	cpu, mem := CPUDistribution(pods), MemDistribution(pods)
+
+	for i := range pods {
+		pods[i].Outlier = (cpu.Median > 0 && float64(pods[i].CPU) > OutlierFactor*float64(cpu.Median)) ||
+			(mem.Median > 0 && float64(pods[i].Mem) > OutlierFactor*float64(mem.Median))
+	}
+}
+
+// Outliers returns the names of the outlier pods.
+func Outliers(pods []PodUsage) []string {
+	var names []string
+
+	for _, p := range pods {
+		if p.Outlier {
+			names = append(names, p.Pod)
+		}
+	}
+
+	return names
+}
+
+func distribution(pods []PodUsage, value func(PodUsage) int64) Distribution {
+	if len(pods) == 0 {
+		return Distribution{}
+	}
+
+	values := make([]int64, 0, len(pods))
+	for _, p := range pods {
+		values = append(values, value(p))
+	}
+
+	slices.Sort(values)
+
+	median := values[len(values)/2]
+	if len(values)%2 == 0 {
+		median = (values[len(values)/2-1] + values[len(values)/2]) / 2
+	}
+
+	return Distribution{Min: values[0], Median: median, Max: values[len(values)-1]}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// ContainerResources are the requests and limits of a container.
+type ContainerResources struct {
+	CPURequest int64 // millicores
+	MemRequest int64 // bytes
+	CPULimit   int64 // millicores
+	MemLimit   int64 // bytes
+}
+
+// InitContainerResources returns the requests and limits of the init containers of the pod spec.
+func InitContainerResources(spec *v1.PodSpec) []ContainerResources {
+	var init []ContainerResources
+
+	for _, c := range spec.InitContainers {
+		init = append(init, ContainerResources{
+			CPURequest: c.Resources.Requests.Cpu().MilliValue(),
+			MemRequest: c.Resources.Requests.Memory().Value(),
+			CPULimit:   c.Resources.Limits.Cpu().MilliValue(),
+			MemLimit:   c.Resources.Limits.Memory().Value(),
+		})
+	}
+
+	return init
+}
+
+// SetQoSClass sets the QoS class of each workload, computed from the requests and limits of all its containers,
+// init containers included.
+func SetQoSClass(res []ResourceInfo) {
+	type workload struct {
+		kind, name string
+	}
+
+	classes := map[workload]v1.PodQOSClass{}
+
+	for _, r := range res {
+		key := workload{r.Kind, r.Name}
+
+		containers := append([]ContainerResources{{
+			CPURequest: r.CPURequest, MemRequest: r.MemRequest, CPULimit: r.CPULimit, MemLimit: r.MemLimit,
+		}}, r.InitContainers...)
+
+		for _, c := range containers {
+			class := containerQoSClass(c)
+
+			switch current, ok := classes[key]; {
+			case !ok:
+				classes[key] = class
+			case current != class:
+				classes[key] = v1.PodQOSBurstable
+			}
+		}
+	}
+
+	for i := range res {
+		res[i].QoSClass = string(classes[workload{res[i].Kind, res[i].Name}])
+	}
+}
+
+// containerQoSClass returns the QoS class the pod would have with this container alone.
+// Requests default to limits when only limits are set, as the API server does.
+func containerQoSClass(r ContainerResources) v1.PodQOSClass {
+	if r.CPURequest == 0 && r.MemRequest == 0 && r.CPULimit == 0 && r.MemLimit == 0 {
+		return v1.PodQOSBestEffort
+	}
+
+	if r.CPULimit > 0 && r.MemLimit > 0 &&
+		(r.CPURequest == 0 || r.CPURequest == r.CPULimit) &&
+		(r.MemRequest == 0 || r.MemRequest == r.MemLimit) {
+		return v1.PodQOSGuaranteed
+	}
+
+	return v1.PodQOSBurstable
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+func TestSetQoSClass(t *testing.T) {
+	const mi = 1024 * 1024
+
+	guaranteed := resources.ContainerResources{CPURequest: 100, MemRequest: 64 * mi, CPULimit: 100, MemLimit: 64 * mi}
+
+	tests := []struct {
+		name   string
+		res    []resources.ResourceInfo
+		expect string
+	}{
+		{
+			name: "guaranteed",
+			res: []resources.ResourceInfo{
+				{Container: "db", CPURequest: 2000, MemRequest: 4096 * mi, CPULimit: 2000, MemLimit: 4096 * mi},
+				{Container: "exporter", CPULimit: 100, MemLimit: 128 * mi},
+			},
+			expect: "Guaranteed",
+		},
+		{
+			name: "guaranteed with init containers",
+			res: []resources.ResourceInfo{
+				{
+					Container: "db", CPURequest: 2000, MemRequest: 4096 * mi, CPULimit: 2000, MemLimit: 4096 * mi,
+					InitContainers: []resources.ContainerResources{guaranteed},
+				},
+			},
+			expect: "Guaranteed",
+		},
+		{
+			name: "guaranteed with a best effort init container",
+			res: []resources.ResourceInfo{
+				{
+					Container: "db", CPURequest: 2000, MemRequest: 4096 * mi, CPULimit: 2000, MemLimit: 4096 * mi,
+					InitContainers: []resources.ContainerResources{guaranteed, {}},
+				},
+			},
+			expect: "Burstable",
+		},
+		{
+			name: "burstable",
+			res: []resources.ResourceInfo{
+				{Container: "web", CPURequest: 100, MemRequest: 128 * mi, CPULimit: 500, MemLimit: 128 * mi},
+			},
+			expect: "Burstable",
+		},
+		{
+			name: "burstable without memory limit",
+			res: []resources.ResourceInfo{
+				{Container: "web", CPURequest: 100, MemRequest: 128 * mi, CPULimit: 100},
+			},
+			expect: "Burstable",
+		},
+		{
+			name: "guaranteed and best effort containers",
+			res: []resources.ResourceInfo{
+				{Container: "web", CPURequest: 100, MemRequest: 128 * mi, CPULimit: 100, MemLimit: 128 * mi},
+				{Container: "sidecar"},
+			},
+			expect: "Burstable",
+		},
+		{
+			name: "best effort",
+			res: []resources.ResourceInfo{
+				{Container: "web"},
+				{Container: "sidecar", InitContainers: []resources.ContainerResources{{}}},
+			},
+			expect: "BestEffort",
+		},
+		{
+			name: "best effort with an init container requests",
+			res: []resources.ResourceInfo{
+				{Container: "web", InitContainers: []resources.ContainerResources{{CPURequest: 50}}},
+			},
+			expect: "Burstable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := make([]resources.ResourceInfo, 0, len(tt.res)+1)
+
+			for _, r := range tt.res {
+				r.Kind, r.Name = "Deployment", "app"
+				res = append(res, r)
+			}
+
+			// Another workload does not change the class.
+			res = append(res, resources.ResourceInfo{Kind: "Deployment", Name: "other", Container: "other", CPURequest: 100})
+
+			resources.SetQoSClass(res)
+
+			for _, r := range res[:len(tt.res)] {
+				assert.Equal(t, tt.expect, r.QoSClass, r.Container)
+			}
+
+			assert.Equal(t, "Burstable", res[len(tt.res)].QoSClass)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// SchedulingConstraints are the pod constraints which restrict the nodes the workload can run on.
+type SchedulingConstraints struct {
+	NodeSelector map[string]string
+	// RequiredNodeAffinity is the node affinity required during scheduling
+	RequiredNodeAffinity *v1.NodeSelector
+	Tolerations          []v1.Toleration
+}
+
+// SchedulingFromPodSpec returns the scheduling constraints of the pod spec.
+func SchedulingFromPodSpec(spec *v1.PodSpec) *SchedulingConstraints {
+	s := &SchedulingConstraints{
+		NodeSelector: spec.NodeSelector,
+		Tolerations:  spec.Tolerations,
+	}
+
+	if spec.Affinity != nil && spec.Affinity.NodeAffinity != nil {
+		s.RequiredNodeAffinity = spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	}
+
+	return s
+}
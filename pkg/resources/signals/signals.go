@@ -0,0 +1,219 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package signals attaches the scheduling failures, evictions, container restarts and OOM kills
+// of the workload pods to the resource rows.
+package signals
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const reasonOOMKilled = "OOMKilled"
+
+var (
+	insufficientRe       = regexp.MustCompile(`Insufficient ([a-zA-Z0-9./-]*[a-zA-Z0-9])`)
+	lowOnResourceRe      = regexp.MustCompile(`low on resource: ([a-zA-Z0-9./-]*[a-zA-Z0-9])`)
+	offendingContainerRe = regexp.MustCompile(`Container (\S+) was using`)
+)
+
+// podSignal is a scheduling or eviction event of a pod, optionally narrowed down to specific containers.
+type podSignal struct {
+	event      resources.PodEvent
+	containers []string
+}
+
+// Attach looks up FailedScheduling and eviction signals, container restarts
+// and OOM kills of the namespace pods and attaches them to the matching resource rows.
+func Attach(ctx context.Context, clientset kubernetes.Interface, namespace string, res []resources.ResourceInfo) {
+	if len(res) == 0 {
+		return
+	}
+
+	var signals []podSignal
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err == nil {
+		for _, pod := range pods.Items {
+			if signal, ok := podStatusSignal(&pod); ok {
+				signals = mergePodSignal(signals, signal)
+			}
+
+			attachContainerRestarts(&pod, res)
+		}
+	}
+
+	events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: "involvedObject.kind=Pod"})
+	if err == nil {
+		for _, event := range events.Items {
+			if signal, ok := eventSignal(&event); ok {
+				signals = mergePodSignal(signals, signal)
+			}
+		}
+	}
+
+	for i := range res {
+		for _, signal := range signals {
+			if !resources.PodBelongsToWorkload(signal.event.Pod, res[i].Kind, res[i].Name) {
+				continue
+			}
+
+			if len(signal.containers) > 0 && !slices.Contains(signal.containers, res[i].Container) {
+				continue
+			}
+
+			res[i].Events = append(res[i].Events, signal.event)
+		}
+	}
+}
+
+// attachContainerRestarts adds the restart and OOM kill counters of the pod containers to the matching rows.
+func attachContainerRestarts(pod *v1.Pod, res []resources.ResourceInfo) {
+	for i := range res {
+		if !resources.PodBelongsToWorkload(pod.Name, res[i].Kind, res[i].Name) {
+			continue
+		}
+
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != res[i].Container {
+				continue
+			}
+
+			res[i].Restarts += status.RestartCount
+
+			if terminated := status.LastTerminationState.Terminated; terminated != nil && terminated.Reason == reasonOOMKilled {
+				res[i].OOMKills++
+			}
+		}
+	}
+}
+
+// podStatusSignal returns the signal of a pod which is currently unschedulable or was evicted.
+func podStatusSignal(pod *v1.Pod) (podSignal, bool) {
+	if pod.Status.Phase == v1.PodFailed && pod.Status.Reason == resources.EventEvicted {
+		return evictionSignal(pod.Name, pod.Status.Message, nil, 0), true
+	}
+
+	if pod.Status.Phase == v1.PodPending {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == v1.PodScheduled && cond.Status == v1.ConditionFalse && cond.Reason == v1.PodReasonUnschedulable {
+				return schedulingSignal(pod.Name, cond.Message, 0)
+			}
+		}
+	}
+
+	return podSignal{}, false
+}
+
+// eventSignal converts a Kubernetes event into a pod signal.
+func eventSignal(event *v1.Event) (podSignal, bool) {
+	count := event.Count
+	if event.Series != nil && event.Series.Count > count {
+		count = event.Series.Count
+	}
+
+	switch event.Reason {
+	case resources.EventFailedScheduling:
+		return schedulingSignal(event.InvolvedObject.Name, event.Message, count)
+	case resources.EventEvicted:
+		var containers []string
+		if offending := event.Annotations["offending_containers"]; offending != "" {
+			containers = strings.Split(offending, ",")
+		}
+
+		return evictionSignal(event.InvolvedObject.Name, event.Message, containers, count), true
+	}
+
+	return podSignal{}, false
+}
+
+// schedulingSignal returns a FailedScheduling signal if the message reports insufficient node resources.
+func schedulingSignal(pod, message string, count int32) (podSignal, bool) {
+	var res []string
+
+	for _, match := range insufficientRe.FindAllStringSubmatch(message, -1) {
+		if !slices.Contains(res, match[1]) {
+			res = append(res, match[1])
+		}
+	}
+
+	if len(res) == 0 {
+		return podSignal{}, false
+	}
+
+	return podSignal{
+		event: resources.PodEvent{
+			Pod:       pod,
+			Reason:    resources.EventFailedScheduling,
+			Resources: res,
+			Message:   message,
+			Count:     count,
+		},
+	}, true
+}
+
+// evictionSignal returns an Evicted signal, detecting node-pressure evictions made by the kubelet.
+func evictionSignal(pod, message string, containers []string, count int32) podSignal {
+	signal := podSignal{
+		event: resources.PodEvent{
+			Pod:     pod,
+			Reason:  resources.EventEvicted,
+			Message: message,
+			Count:   count,
+		},
+		containers: containers,
+	}
+
+	if match := lowOnResourceRe.FindStringSubmatch(message); match != nil {
+		signal.event.NodePressure = true
+		signal.event.Resources = []string{match[1]}
+	}
+
+	if len(signal.containers) == 0 {
+		for _, match := range offendingContainerRe.FindAllStringSubmatch(message, -1) {
+			signal.containers = append(signal.containers, match[1])
+		}
+	}
+
+	return signal
+}
+
+// mergePodSignal adds the signal to the list, merging it with an existing signal of the same pod and reason.
+func mergePodSignal(signals []podSignal, signal podSignal) []podSignal {
+	for i := range signals {
+		if signals[i].event.Pod != signal.event.Pod || signals[i].event.Reason != signal.event.Reason {
+			continue
+		}
+
+		signals[i].event.Count = max(signals[i].event.Count, signal.event.Count)
+		if len(signals[i].containers) == 0 {
+			signals[i].containers = signal.containers
+		}
+
+		return signals
+	}
+
+	return append(signals, signal)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signals_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+	"github.com/sergelogvinov/helm-resources/pkg/resources/signals"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func pendingPod(name, message string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+			Conditions: []v1.PodCondition{{
+				Type: v1.PodScheduled, Status: v1.ConditionFalse, Reason: v1.PodReasonUnschedulable, Message: message,
+			}},
+		},
+	}
+}
+
+func podEvent(name, pod, reason, message string, count int32, annotations map[string]string) *v1.Event {
+	return &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "prod", Annotations: annotations},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: pod, Namespace: "prod"},
+		Reason:         reason,
+		Message:        message,
+		Count:          count,
+	}
+}
+
+func TestAttach(t *testing.T) {
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		res      resources.ResourceInfo
+		expected []resources.PodEvent
+	}{
+		{
+			name: "insufficient resources of a pending pod",
+			objects: []runtime.Object{
+				pendingPod("web-7d9f8b6c5d-abcde", "0/3 nodes are available: 2 Insufficient cpu, 3 Insufficient memory, 1 Insufficient nvidia.com/gpu."),
+			},
+			res: resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: "web"},
+			expected: []resources.PodEvent{{
+				Pod:       "web-7d9f8b6c5d-abcde",
+				Reason:    resources.EventFailedScheduling,
+				Resources: []string{"cpu", "memory", "nvidia.com/gpu"},
+				Message:   "0/3 nodes are available: 2 Insufficient cpu, 3 Insufficient memory, 1 Insufficient nvidia.com/gpu.",
+			}},
+		},
+		{
+			name: "scheduling failure without insufficient resources",
+			objects: []runtime.Object{
+				pendingPod("web-7d9f8b6c5d-abcde", "0/3 nodes are available: 3 node(s) didn't match Pod's node affinity/selector."),
+			},
+			res: resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: "web"},
+		},
+		{
+			name: "pod status and event of the same pod are merged",
+			objects: []runtime.Object{
+				pendingPod("web-7d9f8b6c5d-abcde", "0/3 nodes are available: 3 Insufficient memory."),
+				podEvent("e1", "web-7d9f8b6c5d-abcde", resources.EventFailedScheduling, "0/3 nodes are available: 3 Insufficient memory.", 7, nil),
+			},
+			res: resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: "web"},
+			expected: []resources.PodEvent{{
+				Pod:       "web-7d9f8b6c5d-abcde",
+				Reason:    resources.EventFailedScheduling,
+				Resources: []string{"memory"},
+				Message:   "0/3 nodes are available: 3 Insufficient memory.",
+				Count:     7,
+			}},
+		},
+		{
+			name: "node pressure eviction of the offending container",
+			objects: []runtime.Object{
+				podEvent("e1", "db-0", resources.EventEvicted,
+					"The node was low on resource: memory. Threshold quantity: 100Mi, available: 50Mi. Container db was using 2Gi, request is 1Gi.", 1, nil),
+			},
+			res: resources.ResourceInfo{Kind: "StatefulSet", Name: "db", Container: "db"},
+			expected: []resources.PodEvent{{
+				Pod:          "db-0",
+				Reason:       resources.EventEvicted,
+				Resources:    []string{"memory"},
+				NodePressure: true,
+				Message:      "The node was low on resource: memory. Threshold quantity: 100Mi, available: 50Mi. Container db was using 2Gi, request is 1Gi.",
+				Count:        1,
+			}},
+		},
+		{
+			name: "eviction of another container",
+			objects: []runtime.Object{
+				podEvent("e1", "db-0", resources.EventEvicted, "The node was low on resource: ephemeral-storage.", 1,
+					map[string]string{"offending_containers": "sidecar"}),
+			},
+			res: resources.ResourceInfo{Kind: "StatefulSet", Name: "db", Container: "db"},
+		},
+		{
+			name: "pods of a workload with a longer name",
+			objects: []runtime.Object{
+				pendingPod("web-api-7d9f8b6c5d-abcde", "0/3 nodes are available: 3 Insufficient cpu."),
+			},
+			res: resources.ResourceInfo{Kind: "Deployment", Name: "web", Container: "web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := []resources.ResourceInfo{tt.res}
+
+			signals.Attach(t.Context(), fake.NewClientset(tt.objects...), "prod", res)
+
+			assert.Equal(t, tt.expected, res[0].Events)
+		})
+	}
+}
@@ -19,6 +19,8 @@ package resources
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,15 +36,92 @@ type ResourceInfo struct {
 	Container string `json:"container"`
 	// Labels associated with the workload
 	Labels map[string]string `json:"labels,omitempty"`
+	// WorkloadLabels are all labels of the workload and its pod template, used to match sizing policies
+	WorkloadLabels map[string]string `json:"-"`
+	// MetricsSource is the metrics source of the usage, e.g. "prometheus" or "metrics-server"
+	MetricsSource string `json:"metrics_source,omitempty"`
 	// Usage
 	CPUUsage int64 `json:"cpu_usage,omitempty"`    // millicores
 	MemUsage int64 `json:"memory_usage,omitempty"` // bytes
+	// Usage statistics over the metrics window, keyed by statistic name, e.g. "p95"
+	CPUStats map[string]int64 `json:"cpu_stats,omitempty"`    // millicores
+	MemStats map[string]int64 `json:"memory_stats,omitempty"` // bytes
+	// Usage of every pod of the workload
+	Pods []PodUsage `json:"pods,omitempty"`
+	// VPA recommendation of the container, nil when no VerticalPodAutoscaler targets the workload
+	VPA *VPARecommendation `json:"vpa,omitempty"`
+	// Cost of the container over the metrics window, nil when the metrics source has no cost
+	Cost *Cost `json:"cost,omitempty"`
 	// Requests
 	CPURequest int64 `json:"cpu_request,omitempty"`    // millicores
 	MemRequest int64 `json:"memory_request,omitempty"` // bytes
 	// Limits
 	CPULimit int64 `json:"cpu_limit,omitempty"`    // millicores
 	MemLimit int64 `json:"memory_limit,omitempty"` // bytes
+	// Autoscaler scaling the workload, nil when the workload is not autoscaled
+	Autoscaler *Autoscaler `json:"autoscaler,omitempty"`
+	// Scheduling constraints of the workload pods, nil means any node
+	Scheduling *SchedulingConstraints `json:"-"`
+	// InitContainers are the requests and limits of the init containers of the pods, they count for the QoS class
+	InitContainers []ContainerResources `json:"-"`
+	// QoSClass of the workload pods: Guaranteed, Burstable or BestEffort
+	QoSClass string `json:"qos_class,omitempty"`
+	// Scheduling and eviction signals of the workload pods
+	Events []PodEvent `json:"events,omitempty"`
+	// Stability signals of the container across all pods
+	Restarts      int32   `json:"restarts,omitempty"`
+	OOMKills      int32   `json:"oom_kills,omitempty"`
+	CPUThrottling float64 `json:"cpu_throttling,omitempty"` // ratio of throttled CFS periods
+	// Risk of the current resource settings
+	RiskScore   int      `json:"risk_score,omitempty"`
+	Severity    string   `json:"severity,omitempty"`
+	RiskReasons []string `json:"risk_reasons,omitempty"`
+}
+
+// Autoscaler represents a HorizontalPodAutoscaler or a KEDA ScaledObject targeting the workload.
+type Autoscaler struct {
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	MinReplicas     int32  `json:"min_replicas"`
+	MaxReplicas     int32  `json:"max_replicas"`
+	CurrentReplicas int32  `json:"current_replicas"`
+	// CPUUtilization is the target average CPU utilization, in percent of the CPU requests
+	CPUUtilization int32 `json:"cpu_utilization,omitempty"`
+	// CPUContainer is set when the utilization is of a single container, not of the whole pod
+	CPUContainer string `json:"cpu_container,omitempty"`
+}
+
+// ContainerUsage represents the observed usage of a container.
+type ContainerUsage struct {
+	Source        string
+	CPU           int64 // millicores
+	Mem           int64 // bytes
+	CPUThrottling float64
+	CPUStats      map[string]int64 // millicores
+	MemStats      map[string]int64 // bytes
+	Pods          []PodUsage
+	VPA           *VPARecommendation
+	Cost          *Cost
+}
+
+// Cost represents the cost of a container over a time window, in the currency of the cost source.
+type Cost struct {
+	Window string  `json:"window"`
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memory"`
+	Total  float64 `json:"total"`
+}
+
+// PodEvent represents a scheduling or eviction signal observed for a pod of the workload.
+type PodEvent struct {
+	Pod    string `json:"pod"`
+	Reason string `json:"reason"`
+	// Resources which caused the event, e.g. cpu or memory
+	Resources []string `json:"resources,omitempty"`
+	// NodePressure is true when the kubelet evicted the pod because the node was low on resources
+	NodePressure bool   `json:"node_pressure,omitempty"`
+	Message      string `json:"message,omitempty"`
+	Count        int32  `json:"count,omitempty"`
 }
 
 // ResourceRecommendation represents resource recommendation for a container within a workload.
@@ -64,6 +143,172 @@ type ResourceRecommendation struct {
 	RecommendedCPULimit int64 // millicores
 	CurrentMemLimit     int64 // bytes
 	RecommendedMemLimit int64 // bytes
+	// RecommendedReplicas is set when the workload should scale out instead of up
+	RecommendedReplicas int32
+	// CPU utilization target of the autoscaler, recommended to keep the scaling threshold when CPU requests change
+	CurrentCPUUtilization     int32
+	RecommendedCPUUtilization int32
+	// RemoveCPULimit is set when the CPU limit should be removed
+	RemoveCPULimit bool
+	// Notes explain conditions the recommendation cannot fix by itself
+	Notes []string
+	// Risk of the current resource settings
+	RiskScore int
+	Severity  string
+	// Escalation is set when the recommendation was capped by the sizing policy
+	Escalation bool
+	// Requests released by downsizing, multiplied by replicas
+	ReclaimableCPU int64 // millicores
+	ReclaimableMem int64 // bytes
+}
+
+const (
+	// StatisticAvg is the average usage over the metrics window.
+	StatisticAvg = "avg"
+	// StatisticMax is the maximum usage over the metrics window.
+	StatisticMax = "max"
+	// StatisticStdDev is the standard deviation of usage over the metrics window.
+	StatisticStdDev = "stddev"
+)
+
+const (
+	// AutoscalerHPA is the kind of the HorizontalPodAutoscaler.
+	AutoscalerHPA = "HorizontalPodAutoscaler"
+	// AutoscalerKEDA is the kind of the KEDA ScaledObject.
+	AutoscalerKEDA = "ScaledObject"
+)
+
+const (
+	// EventFailedScheduling is the reason of the event emitted when a pod does not fit on any node.
+	EventFailedScheduling = "FailedScheduling"
+	// EventEvicted is the reason of the event emitted when a pod was evicted from a node.
+	EventEvicted = "Evicted"
+)
+
+// SetUsage sets the observed usage of the container.
+func (r *ResourceInfo) SetUsage(usage ContainerUsage) {
+	r.MetricsSource = usage.Source
+	r.CPUUsage = usage.CPU
+	r.MemUsage = usage.Mem
+	r.CPUThrottling = usage.CPUThrottling
+	r.CPUStats = usage.CPUStats
+	r.MemStats = usage.MemStats
+	r.Pods = usage.Pods
+	r.VPA = usage.VPA
+	r.Cost = usage.Cost
+}
+
+// HasChanges reports whether the recommendation changes any requests or limits.
+func (r ResourceRecommendation) HasChanges() bool {
+	return r.RecommendedCPURequest > 0 || r.RecommendedMemRequest > 0 ||
+		r.RecommendedCPULimit > 0 || r.RecommendedMemLimit > 0 || r.RemoveCPULimit || r.RecommendedReplicas > 0
+}
+
+// RaisesRequests reports whether the recommendation raises the requests of the workload, per pod or by scaling out.
+func (r ResourceRecommendation) RaisesRequests() bool {
+	return r.RecommendedCPURequest > r.CurrentCPURequest || r.RecommendedMemRequest > r.CurrentMemRequest ||
+		r.RecommendedReplicas > 0
+}
+
+// Quantile parses a percentile statistic, e.g. "p95" or "p99.9", into a quantile between 0 and 1.
+func Quantile(stat string) (float64, bool) {
+	value, found := strings.CutPrefix(stat, "p")
+	if !found {
+		return 0, false
+	}
+
+	percentile, err := strconv.ParseFloat(value, 64)
+	if err != nil || percentile <= 0 || percentile >= 100 {
+		return 0, false
+	}
+
+	return percentile / 100, true
+}
+
+// ValidStatistic reports whether the statistic name is supported.
+func ValidStatistic(stat string) bool {
+	switch stat {
+	case StatisticAvg, StatisticMax, StatisticStdDev:
+		return true
+	}
+
+	if IsVPAStatistic(stat) {
+		return true
+	}
+
+	_, ok := Quantile(stat)
+
+	return ok
+}
+
+var (
+	// podOrdinalRe is the suffix of StatefulSet pods and CNPG instances, "<ordinal>".
+	podOrdinalRe = regexp.MustCompile(`^[0-9]+$`)
+	// podRandomRe is the suffix of DaemonSet, Job and ReplicaSet pods, "<random>".
+	podRandomRe = regexp.MustCompile(`^[a-z0-9]{5}$`)
+	// podTemplateHashRe is the suffix of Deployment pods, "<pod-template-hash>-<random>".
+	podTemplateHashRe = regexp.MustCompile(`^[a-z0-9]{1,10}-[a-z0-9]{5}$`)
+	// cronJobPodRe is the suffix of CronJob pods, "<scheduled time>-<random>", or "<random>" of the pods of a manual Job.
+	cronJobPodRe = regexp.MustCompile(`^([0-9]{8,10}-)?[a-z0-9]{5}$`)
+	// clickHousePodRe is the suffix of ClickHouseInstallation pods, "<cluster>-<shard>-<replica>-<ordinal>".
+	clickHousePodRe = regexp.MustCompile(`-[0-9]+-[0-9]+-[0-9]+$`)
+)
+
+// PodBelongsToWorkload reports whether a pod name belongs to the given workload.
+// The name suffix after the workload name must have the shape of the pods the controller of the kind creates,
+// so the pods of a workload web-api do not belong to a workload web.
+func PodBelongsToWorkload(podName, kind, workloadName string) bool {
+	if podName == workloadName {
+		return true
+	}
+
+	if kind == "ClickHouseInstallation" {
+		// ClickHouseInstallation pods are named "chi-<installation>-<cluster>-<shard>-<replica>-<ordinal>".
+		return (strings.HasPrefix(podName, "chi-"+workloadName+"-") || strings.HasPrefix(podName, workloadName+"-")) &&
+			clickHousePodRe.MatchString(podName)
+	}
+
+	suffix, ok := strings.CutPrefix(podName, workloadName+"-")
+	if !ok || suffix == "" {
+		return false
+	}
+
+	switch kind {
+	case "StatefulSet", "Cluster":
+		// StatefulSet pods and CNPG instances are named "<workload>-<ordinal>".
+		return podOrdinalRe.MatchString(suffix)
+	case "Deployment", "Pooler":
+		// Deployment and CNPG Pooler pods are named "<workload>-<pod-template-hash>-<random>".
+		return podTemplateHashRe.MatchString(suffix)
+	case "DaemonSet", "Job", "ReplicaSet":
+		return podRandomRe.MatchString(suffix)
+	case "CronJob":
+		return cronJobPodRe.MatchString(suffix)
+	default:
+		// Other controllers name their pods differently, match by prefix.
+		return true
+	}
+}
+
+// PodNamePattern returns the regular expression of the pod names of the workload, see PodBelongsToWorkload.
+// It is used in the pod label matchers of Prometheus queries, which anchor it at both ends.
+func PodNamePattern(kind, workloadName string) string {
+	name := regexp.QuoteMeta(workloadName)
+
+	switch kind {
+	case "ClickHouseInstallation":
+		return fmt.Sprintf(`(chi-)?%s(-.+)?-[0-9]+-[0-9]+-[0-9]+`, name)
+	case "StatefulSet", "Cluster":
+		return name + `-[0-9]+`
+	case "Deployment", "Pooler":
+		return name + `-[a-z0-9]{1,10}-[a-z0-9]{5}`
+	case "DaemonSet", "Job", "ReplicaSet":
+		return name + `-[a-z0-9]{5}`
+	case "CronJob":
+		return name + `-([0-9]{8,10}-)?[a-z0-9]{5}`
+	default:
+		return name + `-.+`
+	}
 }
 
 // FilterLabels filters the provided labels to include only common labels used for identifying workloads.
@@ -0,0 +1,83 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergelogvinov/helm-resources/pkg/resources"
+)
+
+func TestPodBelongsToWorkload(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      string
+		kind     string
+		workload string
+		expected bool
+	}{
+		{name: "deployment pod", pod: "web-7d9f8b6c5d-abcde", kind: "Deployment", workload: "web", expected: true},
+		{name: "deployment pod of a longer name", pod: "web-api-7d9f8b6c5d-abcde", kind: "Deployment", workload: "web"},
+		{name: "statefulset pod", pod: "db-0", kind: "StatefulSet", workload: "db", expected: true},
+		{name: "statefulset pod of a longer name", pod: "db-backup-0", kind: "StatefulSet", workload: "db"},
+		{name: "daemonset pod", pod: "agent-x7k2p", kind: "DaemonSet", workload: "agent", expected: true},
+		{name: "daemonset pod of a deployment", pod: "agent-7d9f8b6c5d-x7k2p", kind: "DaemonSet", workload: "agent"},
+		{name: "cronjob pod", pod: "backup-29012345-x7k2p", kind: "CronJob", workload: "backup", expected: true},
+		{name: "cnpg instance", pod: "pg-1", kind: "Cluster", workload: "pg", expected: true},
+		{name: "clickhouse pod", pod: "chi-ch-main-0-1-0", kind: "ClickHouseInstallation", workload: "ch", expected: true},
+		{name: "clickhouse pod of another installation", pod: "chi-chx-main-0-1-0", kind: "ClickHouseInstallation", workload: "ch"},
+		{name: "pod of another workload", pod: "api-7d9f8b6c5d-abcde", kind: "Deployment", workload: "web"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resources.PodBelongsToWorkload(tt.pod, tt.kind, tt.workload))
+
+			pattern := regexp.MustCompile("^" + resources.PodNamePattern(tt.kind, tt.workload) + "$")
+			assert.Equal(t, tt.expected, pattern.MatchString(tt.pod))
+		})
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	tests := []struct {
+		stat   string
+		expect float64
+		ok     bool
+	}{
+		{stat: "p50", expect: 0.5, ok: true},
+		{stat: "p95", expect: 0.95, ok: true},
+		{stat: "p99.9", expect: 0.999, ok: true},
+		{stat: "p0"},
+		{stat: "p100"},
+		{stat: "p"},
+		{stat: "95"},
+		{stat: "pmax"},
+		{stat: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stat, func(t *testing.T) {
+			q, ok := resources.Quantile(tt.stat)
+			assert.Equal(t, tt.ok, ok)
+			assert.InDelta(t, tt.expect, q, 1e-9)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2026 Serge Logvinov.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+const (
+	// StatisticVPALowerBound is the lower bound of the VerticalPodAutoscaler recommendation.
+	StatisticVPALowerBound = "vpa_lower_bound"
+	// StatisticVPATarget is the target of the VerticalPodAutoscaler recommendation.
+	StatisticVPATarget = "vpa_target"
+	// StatisticVPAUpperBound is the upper bound of the VerticalPodAutoscaler recommendation.
+	StatisticVPAUpperBound = "vpa_upper_bound"
+	// StatisticVPAUncappedTarget is the target of the VerticalPodAutoscaler recommendation before the resource policy caps.
+	StatisticVPAUncappedTarget = "vpa_uncapped_target"
+)
+
+// VPARecommendation represents the recommendation of a VerticalPodAutoscaler for a container.
+type VPARecommendation struct {
+	// Name of the VerticalPodAutoscaler
+	Name           string         `json:"name"`
+	LowerBound     ResourceValues `json:"lower_bound"`
+	Target         ResourceValues `json:"target"`
+	UpperBound     ResourceValues `json:"upper_bound"`
+	UncappedTarget ResourceValues `json:"uncapped_target"`
+}
+
+// ResourceValues represents CPU and memory values of a container.
+type ResourceValues struct {
+	CPU int64 `json:"cpu,omitempty"`    // millicores
+	Mem int64 `json:"memory,omitempty"` // bytes
+}
+
+// IsVPAStatistic reports whether the statistic is a VerticalPodAutoscaler recommendation.
+func IsVPAStatistic(stat string) bool {
+	switch stat {
+	case StatisticVPALowerBound, StatisticVPATarget, StatisticVPAUpperBound, StatisticVPAUncappedTarget:
+		return true
+	}
+
+	return false
+}
+
+// CPUStatistics returns the CPU recommendation keyed by the VPA statistic names.
+func (v *VPARecommendation) CPUStatistics() map[string]int64 {
+	return v.statistics(func(r ResourceValues) int64 { return r.CPU })
+}
+
+// MemStatistics returns the memory recommendation keyed by the VPA statistic names.
+func (v *VPARecommendation) MemStatistics() map[string]int64 {
+	return v.statistics(func(r ResourceValues) int64 { return r.Mem })
+}
+
+func (v *VPARecommendation) statistics(value func(ResourceValues) int64) map[string]int64 {
+	if v == nil {
+		return nil
+	}
+
+	stats := map[string]int64{}
+
+	for stat, r := range map[string]ResourceValues{
+		StatisticVPALowerBound:     v.LowerBound,
+		StatisticVPATarget:         v.Target,
+		StatisticVPAUpperBound:     v.UpperBound,
+		StatisticVPAUncappedTarget: v.UncappedTarget,
+	} {
+		if value(r) > 0 {
+			stats[stat] = value(r)
+		}
+	}
+
+	return stats
+}